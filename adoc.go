@@ -0,0 +1,65 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// CreateAsciiDoc generates the TOC as an AsciiDoc document: the root is the document title,
+// every directory is a section whose level follows its depth (`==`, `===`, ...) and the files
+// are list items with `xref:` macros, listed before the subsections of their directory. The
+// targets have their extension replaced with `.adoc`, as the Markdown files are expected to be
+// converted alongside the TOC.
+//
+// Parameters:
+// - md: the MDFileInfo object representing the root directory.
+// - opts: the options used to render the TOC.
+//
+// Returns:
+// - string: the generated AsciiDoc.
+func CreateAsciiDoc(md MDFileInfo, opts TocOptions) string {
+	var sb strings.Builder
+	sb.WriteString("= " + md.Title + "\n")
+	var walk func(node MDFileInfo)
+	walk = func(node MDFileInfo) {
+		keys := SortedChildKeys(node, opts)
+		// The files come first, anything after a section heading would belong to that section
+		listed := false
+		for _, key := range keys {
+			if child := node.Children[key]; !child.IsDir {
+				if !listed {
+					sb.WriteString("\n")
+					listed = true
+				}
+				sb.WriteString("* " + adocXref(child.Title, child.Path) + "\n")
+			}
+		}
+		for _, key := range keys {
+			child := node.Children[key]
+			if !child.IsDir {
+				continue
+			}
+			// AsciiDoc supports section levels up to 5
+			level := child.Level
+			if level > 5 {
+				level = 5
+			}
+			title := child.Title
+			if child.LinkPath != "" {
+				title = adocXref(child.Title, child.LinkPath)
+			}
+			sb.WriteString("\n" + strings.Repeat("=", level+1) + " " + title + "\n")
+			walk(child)
+		}
+	}
+	walk(md)
+	return sb.String()
+}
+
+// adocXref renders an `xref:` macro to the AsciiDoc counterpart of the given escaped path.
+func adocXref(title, escapedPath string) string {
+	target := RelPath(MDFileInfo{Path: escapedPath})
+	target = strings.TrimSuffix(target, filepath.Ext(target)) + ".adoc"
+	target = strings.ReplaceAll(target, " ", "%20")
+	return "xref:" + target + "[" + strings.ReplaceAll(title, "]", "\\]") + "]"
+}
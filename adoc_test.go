@@ -0,0 +1,32 @@
+package main
+
+import "testing"
+
+func TestCreateAsciiDoc(t *testing.T) {
+	want := "= Docs\n" +
+		"\n* xref:intro.adoc[Intro]\n" +
+		"\n== guides\n" +
+		"\n* xref:guides/start.adoc[Getting Started]\n" +
+		"\n=== advanced\n" +
+		"\n* xref:guides/advanced/scaling.adoc[Scaling]\n"
+	if got := CreateAsciiDoc(sampleDocs(t), testTocOptions()); got != want {
+		t.Errorf("CreateAsciiDoc() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestAdocXref(t *testing.T) {
+	tests := []struct {
+		title string
+		path  string
+		want  string
+	}{
+		{"Intro", "./intro.md", "xref:intro.adoc[Intro]"},
+		{"Arrays [a]", "./ref/arrays.md", `xref:ref/arrays.adoc[Arrays [a\]]`},
+		{"Spaces", "./my%20docs/a%20b.md", "xref:my%20docs/a%20b.adoc[Spaces]"},
+	}
+	for _, tt := range tests {
+		if got := adocXref(tt.title, tt.path); got != tt.want {
+			t.Errorf("adocXref(%q, %q) = %q, want %q", tt.title, tt.path, got, tt.want)
+		}
+	}
+}
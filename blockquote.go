@@ -0,0 +1,31 @@
+package main
+
+import (
+	"strings"
+)
+
+// CreateBlockquoteTree generates a TOC where the hierarchy is represented by nested blockquotes
+// instead of list indentation, every node is quoted once more than its parent.
+//
+// Each entry is followed by an empty quote line of the same depth, so that the next entry
+// is not merged into its paragraph when the depth decreases.
+//
+// Parameters:
+// - md: the MDFileInfo object representing the file or directory.
+// - opts: the options used to render the TOC.
+//
+// Returns:
+// - string: the generated TOC.
+func CreateBlockquoteTree(md MDFileInfo, opts TocOptions) string {
+	var toc string
+	if md.Level == 0 {
+		toc = RootHeading(md, opts) + "\n"
+	} else {
+		quote := strings.Repeat(">", md.Level)
+		toc = quote + " " + EntryText(md, opts) + "\n" + quote + "\n"
+	}
+	for _, key := range SortedChildKeys(md, opts) {
+		toc += CreateBlockquoteTree(md.Children[key], opts)
+	}
+	return toc
+}
@@ -0,0 +1,15 @@
+package main
+
+import "testing"
+
+func TestCreateBlockquoteTree(t *testing.T) {
+	want := "# Docs\n\n" +
+		"> guides\n>\n" +
+		">> advanced\n>>\n" +
+		">>> [Scaling](./guides/advanced/scaling.md)\n>>>\n" +
+		">> [Getting Started](./guides/start.md)\n>>\n" +
+		"> [Intro](./intro.md)\n>\n"
+	if got := CreateBlockquoteTree(sampleDocs(t), testTocOptions()); got != want {
+		t.Errorf("CreateBlockquoteTree() =\n%s\nwant\n%s", got, want)
+	}
+}
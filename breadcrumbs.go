@@ -0,0 +1,56 @@
+package main
+
+import (
+	"strings"
+	"text/template"
+)
+
+// CreateBreadcrumbs generates one list item per file, showing the path to the file as links
+// separated by ` / `, e.g. `[Guides](guides/README.md) / Advanced / [Scaling](scaling.md)`.
+//
+// A directory links to its README.md or index.md when it has one, with opts.AnchorMode to the
+// anchor of its section in the combined document, otherwise its title is shown without a link.
+// The entries are rendered with the opts.EntryFormat template, DefaultBreadcrumbsEntryFormat if
+// it is nil.
+//
+// Parameters:
+// - md: the MDFileInfo object representing the root directory.
+// - opts: the options used to render the TOC.
+//
+// Returns:
+// - string: the generated breadcrumbs.
+func CreateBreadcrumbs(md MDFileInfo, opts TocOptions) string {
+	tmpl := opts.EntryFormat
+	if tmpl == nil {
+		tmpl = template.Must(template.New("entry").Parse(DefaultBreadcrumbsEntryFormat))
+	}
+	var sb strings.Builder
+	sb.WriteString(RootHeading(md, opts) + "\n")
+	for _, entry := range FlattenFiles(md, opts) {
+		writeEntry(&sb, tmpl, entry.Data(opts))
+	}
+	return sb.String()
+}
+
+// DirLink renders the link to a directory, pointing to its LinkPath or its index file if it has
+// one. With opts.AnchorMode, it points to the anchor of the section of the directory in the
+// combined document instead, see LinkTarget, as a README.md is not part of that document.
+// Otherwise there is nothing to link to and the title is rendered as plain text.
+//
+// Parameters:
+// - md: the MDFileInfo object representing the directory.
+// - opts: the options used to render the TOC.
+//
+// Returns:
+// - string: the rendered link.
+func DirLink(md MDFileInfo, opts TocOptions) string {
+	switch {
+	case opts.AnchorMode:
+		return FormatLink(md, opts)
+	case md.LinkPath != "":
+		return EntryText(md, opts)
+	case md.IndexPath != "":
+		return FormatLink(MDFileInfo{Title: md.Title, Path: md.IndexPath}, opts)
+	}
+	return EscapeLinkText(md.Title)
+}
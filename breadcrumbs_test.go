@@ -0,0 +1,47 @@
+package main
+
+import "testing"
+
+func TestCreateBreadcrumbs(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"intro.md":                   "# Intro\n",
+		"guides/README.md":           "# Guides\n",
+		"guides/start.md":            "# Getting Started\n",
+		"guides/advanced/scaling.md": "# Scaling\n",
+	})
+	md := listTree(t, dir, testListOptions())
+	md.Title = "Docs"
+	tests := []struct {
+		name string
+		opts func(*TocOptions)
+		want string
+	}{
+		{"links", nil, "# Docs\n\n" +
+			"- [guides](./guides/README.md) / advanced / [Scaling](./guides/advanced/scaling.md)\n" +
+			"- [guides](./guides/README.md) / [Getting Started](./guides/start.md)\n" +
+			"- [Intro](./intro.md)\n"},
+		{"anchors", func(opts *TocOptions) { opts.AnchorMode = true }, "# Docs\n\n" +
+			"- [guides](#guides) / [advanced](#advanced) / [Scaling](#file-guides-advanced-scaling)\n" +
+			"- [guides](#guides) / [Getting Started](#file-guides-start)\n" +
+			"- [Intro](#file-intro)\n"},
+		{"namespaced anchors", func(opts *TocOptions) { opts.AnchorMode = true; opts.NamespaceAnchors = true }, "# Docs\n\n" +
+			"- [guides](#file-guides) / [advanced](#file-guides-advanced) / [Scaling](#file-guides-advanced-scaling)\n" +
+			"- [guides](#file-guides) / [Getting Started](#file-guides-start)\n" +
+			"- [Intro](#file-intro)\n"},
+		{"wiki anchors", func(opts *TocOptions) { opts.AnchorMode = true; opts.LinkStyle = LinkStyleWiki }, "# Docs\n\n" +
+			"- [[#guides|guides]] / [[#advanced|advanced]] / [[#file-guides-advanced-scaling|Scaling]]\n" +
+			"- [[#guides|guides]] / [[#file-guides-start|Getting Started]]\n" +
+			"- [[#file-intro|Intro]]\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := testTocOptions()
+			if tt.opts != nil {
+				tt.opts(&opts)
+			}
+			if got := CreateBreadcrumbs(md, opts); got != tt.want {
+				t.Errorf("CreateBreadcrumbs() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"strings"
+)
+
+// CreateChecksums generates the SHA-256 of every file in the format of `sha256sum`,
+// one `<checksum>  <path>` line per file, so that the output can be verified with
+// `sha256sum -c` from the root directory.
+//
+// Parameters:
+// - md: the MDFileInfo object representing the root directory, listed with checksums.
+// - opts: the options used to render the TOC.
+//
+// Returns:
+// - string: the generated checksums.
+func CreateChecksums(md MDFileInfo, opts TocOptions) string {
+	var sb strings.Builder
+	for _, entry := range FlattenFiles(md, opts) {
+		sb.WriteString(entry.File.Checksum + "  " + RelPath(entry.File) + "\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCreateChecksums(t *testing.T) {
+	files := map[string]string{
+		"intro.md":        "# Intro\n",
+		"guides/start.md": "# Getting Started\n\nSome text.\n",
+	}
+	dir := writeTree(t, files)
+	opts := testListOptions()
+	opts.Checksums = true
+	tests := []struct {
+		name   string
+		change string
+	}{
+		{"initial", ""},
+		{"changed content", "# Intro\n\nEdited.\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.change != "" {
+				files["intro.md"] = tt.change
+				if err := os.WriteFile(filepath.Join(dir, "intro.md"), []byte(tt.change), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			sum := func(name string) string {
+				return fmt.Sprintf("%x", sha256.Sum256([]byte(files[name])))
+			}
+			want := sum("guides/start.md") + "  guides/start.md\n" + sum("intro.md") + "  intro.md\n"
+			if got := CreateChecksums(listTree(t, dir, opts), testTocOptions()); got != want {
+				t.Errorf("CreateChecksums() =\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// CopyToClipboard copies the text to the system clipboard. It is a variable so that it can be
+// replaced, e.g. when the clipboard is not available.
+var CopyToClipboard = copyWithCommand
+
+// ClipboardCommand returns the command copying its stdin to the clipboard on this platform:
+// pbcopy on macOS, clip on Windows, and wl-copy, xclip or xsel, whichever is installed, elsewhere.
+//
+// Returns:
+// - []string: the command and its arguments.
+// - error: an error if no clipboard command is available.
+func ClipboardCommand() ([]string, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		return []string{"pbcopy"}, nil
+	case "windows":
+		return []string{"clip"}, nil
+	}
+	candidates := [][]string{
+		{"xclip", "-selection", "clipboard"},
+		{"xsel", "--clipboard", "--input"},
+	}
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		candidates = append([][]string{{"wl-copy"}}, candidates...)
+	}
+	for _, command := range candidates {
+		if _, err := exec.LookPath(command[0]); err == nil {
+			return command, nil
+		}
+	}
+	return nil, errors.New("no clipboard command found, install wl-copy, xclip or xsel")
+}
+
+// copyWithCommand copies the text to the clipboard with the command given by ClipboardCommand.
+func copyWithCommand(text string) error {
+	command, err := ClipboardCommand()
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
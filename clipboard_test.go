@@ -0,0 +1,102 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestClipboardFlag(t *testing.T) {
+	dir := writeTree(t, map[string]string{"intro.md": "# Intro\n"})
+	var copied []string
+	oldCopy := CopyToClipboard
+	defer func() { CopyToClipboard = oldCopy }()
+	CopyToClipboard = func(text string) error {
+		copied = append(copied, text)
+		return nil
+	}
+	want := runMain(t, "-dir", dir, "-t", "Docs")
+	if out := runMain(t, "-dir", dir, "-t", "Docs", "-clipboard"); out != "" {
+		t.Errorf("stdout = %q, want nothing", out)
+	}
+	outFile := filepath.Join(t.TempDir(), "TOC.md")
+	runMain(t, "-dir", dir, "-t", "Docs", "-clipboard", "-out", outFile)
+	content, err := os.ReadFile(outFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != want {
+		t.Errorf("file = %q, want %q", content, want)
+	}
+	if !reflect.DeepEqual(copied, []string{want, want}) {
+		t.Errorf("copied = %q, want %q twice", copied, want)
+	}
+}
+
+// fakeCommand writes an executable shell script to dir which saves its arguments and stdin to
+// <name>.out, with shell builtins only as the tests replace PATH.
+func fakeCommand(t *testing.T, dir, name string) string {
+	t.Helper()
+	out := filepath.Join(dir, name+".out")
+	script := "#!/bin/sh\necho \"$@\" > " + out + "\nwhile IFS= read -r line; do echo \"$line\" >> " + out + "; done\n"
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestClipboardCommand(t *testing.T) {
+	if runtime.GOOS == "darwin" || runtime.GOOS == "windows" {
+		t.Skip("the clipboard command is fixed on " + runtime.GOOS)
+	}
+	tests := []struct {
+		name     string
+		commands []string
+		wayland  string
+		want     []string
+		wantErr  bool
+	}{
+		{"none", nil, "", nil, true},
+		{"xclip", []string{"xclip", "xsel"}, "", []string{"xclip", "-selection", "clipboard"}, false},
+		{"xsel", []string{"xsel"}, "", []string{"xsel", "--clipboard", "--input"}, false},
+		{"wayland", []string{"wl-copy", "xclip"}, "wayland-0", []string{"wl-copy"}, false},
+		{"wl-copy without wayland", []string{"wl-copy", "xclip"}, "", []string{"xclip", "-selection", "clipboard"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bin := t.TempDir()
+			outs := make(map[string]string)
+			for _, command := range tt.commands {
+				outs[command] = fakeCommand(t, bin, command)
+			}
+			t.Setenv("PATH", bin)
+			t.Setenv("WAYLAND_DISPLAY", tt.wayland)
+			got, err := ClipboardCommand()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ClipboardCommand() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ClipboardCommand() = %q, want %q", got, tt.want)
+			}
+			if tt.wantErr {
+				if err := copyWithCommand("# Docs\n"); err == nil {
+					t.Error("copyWithCommand() returned no error")
+				}
+				return
+			}
+			if err := copyWithCommand("# Docs\n"); err != nil {
+				t.Fatal(err)
+			}
+			content, err := os.ReadFile(outs[tt.want[0]])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := strings.Join(tt.want[1:], " ") + "\n# Docs\n"; string(content) != want {
+				t.Errorf("%s got %q, want %q", tt.want[0], content, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CreateConcatenation combines all the files of the tree into one Markdown document, in rendering
+// order, to follow a TOC generated with opts.AnchorMode. Every directory gets a heading and every
+// file an `<a id>` anchor matching FileAnchor, then its body without the frontmatter. The headings
+// of the files are demoted by their depth, so that the H1 of a file at the root becomes an H2 under
+// the title of the document, the H1 of a file in a directory an H3 under the heading of its
+// directory, and so on, capped at H6.
+//
+// Parameters:
+// - dirPath: the directory the tree was listed from.
+// - md: the MDFileInfo object representing the root directory.
+// - opts: the options used to render the TOC.
+//
+// Returns:
+// - string: the combined document, without the title and the TOC.
+// - error: an error if a file could not be read.
+func CreateConcatenation(dirPath string, md MDFileInfo, opts TocOptions) (string, error) {
+	var sb strings.Builder
+	var walk func(node MDFileInfo) error
+	walk = func(node MDFileInfo) error {
+		for _, key := range SortedChildKeys(node, opts) {
+			child := node.Children[key]
+			fmt.Fprintf(&sb, "\n<a id=\"%s\"></a>\n\n", FileAnchor(child, opts))
+			if child.IsDir {
+				fmt.Fprintf(&sb, "%s %s\n", HeadingMarker(child.Level+1), child.Title)
+				if err := walk(child); err != nil {
+					return err
+				}
+				continue
+			}
+			content, err := os.ReadFile(filepath.Join(dirPath, filepath.FromSlash(RelPath(child))))
+			if err != nil {
+				return err
+			}
+			for _, line := range DemoteHeadings(SplitLines(content), child.Level) {
+				sb.WriteString(line + "\n")
+			}
+		}
+		return nil
+	}
+	err := walk(md)
+	return sb.String(), err
+}
+
+// DemoteHeadings returns the body of a Markdown file, without its frontmatter, with its ATX and
+// Setext headers moved the given number of levels deeper, capped at H6. The Setext headers are
+// rewritten as ATX headers. The lines of fenced code blocks are kept as they are.
+//
+// Parameters:
+// - lines: the lines of the Markdown file.
+// - levels: the number of levels the headers are moved by.
+//
+// Returns:
+// - []string: the lines of the demoted body.
+func DemoteHeadings(lines []string, levels int) []string {
+	_, body := SplitFrontmatter(lines)
+	prose := ProseLines(lines)
+	demoted := make([]string, 0, len(body))
+	for i, line := range body {
+		switch {
+		case headingRegex.MatchString(prose[i]):
+			level := len(line) - len(strings.TrimLeft(line, "#"))
+			demoted = append(demoted, HeadingMarker(level+levels)+line[level:])
+		case SetextLevel(prose, i) > 0:
+			// The text of the header was added on the line above
+			level := SetextLevel(prose, i)
+			demoted[len(demoted)-1] = HeadingMarker(level+levels) + " " + strings.TrimSpace(prose[i-1])
+		default:
+			demoted = append(demoted, line)
+		}
+	}
+	return demoted
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDemoteHeadings(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		levels  int
+		want    string
+	}{
+		{"atx", "# Title\n\n## Part\n", 1, "## Title\n\n### Part\n"},
+		{"setext", "Title\n=====\n\nPart\n----\n", 2, "### Title\n\n#### Part\n"},
+		{"thematic break", "# Title\n\n---\n", 1, "## Title\n\n---\n"},
+		{"break after a list", "# Title\n\n- item\n---\n", 1, "## Title\n\n- item\n---\n"},
+		{"text after a blockquote", "> quote\n===\n", 1, "> quote\n===\n"},
+		{"capped", "##### Deep\n", 3, "###### Deep\n"},
+		{"frontmatter", "---\ntitle: x\n---\n# Title\n", 1, "## Title\n"},
+		{"code block", "# Title\n\n```\n# comment\n```\n", 1, "## Title\n\n```\n# comment\n```\n"},
+		{"none", "# Title\n", 0, "# Title\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DemoteHeadings(SplitLines([]byte(tt.content)), tt.levels)
+			if want := SplitLines([]byte(tt.want)); !reflect.DeepEqual(got, want) {
+				t.Errorf("DemoteHeadings() = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestCreateConcatenation(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"intro.md":        "---\ntitle: x\n---\n# Intro\n\nText\n",
+		"guides/start.md": "Start\n=====\n\n## Part\n",
+	})
+	md := listTree(t, dir, testListOptions())
+	opts := testTocOptions()
+	got, err := CreateConcatenation(dir, md, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "\n<a id=\"file-guides\"></a>\n\n## guides\n" +
+		"\n<a id=\"file-guides-start\"></a>\n\n### Start\n\n#### Part\n" +
+		"\n<a id=\"file-intro\"></a>\n\n## Intro\n\nText\n"
+	if got != want {
+		t.Errorf("CreateConcatenation() =\n%s\nwant\n%s", got, want)
+	}
+	// Every link of the TOC in anchor mode points to an anchor of the document
+	opts.AnchorMode = true
+	for _, entry := range FlattenFiles(md, opts) {
+		target := LinkTarget(entry.File, opts)
+		if !strings.Contains(got, "<a id=\""+strings.TrimPrefix(target, "#")+"\"></a>") {
+			t.Errorf("the document has no anchor for %s", target)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileName is the name of the configuration file read from the current directory.
+const ConfigFileName = ".mdtocgen.yaml"
+
+// configOnlyFlags are the flags which cannot be set in the configuration file.
+var configOnlyFlags = map[string]bool{"config": true, "init": true}
+
+// scaffoldFlags are the flags set in the configuration file written by `-init`, the other flags
+// are listed in comments. `sort` is left out, a file setting it counts as given and stops `-hugo`
+// from sorting by weight.
+var scaffoldFlags = map[string]bool{"dir": true, "format": true, "title-strategy": true}
+
+// ApplyConfig sets the flags which were not given on the command line from a YAML configuration
+// file, whose keys are the names of the flags, e.g. `format: html`. A list sets a repeatable flag
+// once per item.
+//
+// Parameters:
+// - flags: the parsed flag set.
+// - filePath: the path of the configuration file.
+// - required: whether a missing file is an error rather than ignored.
+//
+// Returns:
+// - error: an error if the file cannot be read or parsed, or sets an unknown or invalid flag.
+func ApplyConfig(flags *flag.FlagSet, filePath string, required bool) error {
+	content, err := os.ReadFile(filePath)
+	if errors.Is(err, fs.ErrNotExist) && !required {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	var values map[string]interface{}
+	if err := yaml.Unmarshal(content, &values); err != nil {
+		return fmt.Errorf("%s: %w", filePath, err)
+	}
+	set := make(map[string]bool)
+	flags.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	for name, value := range values {
+		if flags.Lookup(name) == nil || configOnlyFlags[name] {
+			return fmt.Errorf("%s: unknown setting %q", filePath, name)
+		}
+		if set[name] || value == nil {
+			continue
+		}
+		items, ok := value.([]interface{})
+		if !ok {
+			items = []interface{}{value}
+		}
+		for _, item := range items {
+			if err := flags.Set(name, fmt.Sprint(item)); err != nil {
+				return fmt.Errorf("%s: %s: %w", filePath, name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// WriteConfigScaffold writes a commented configuration file with the default value of every flag,
+// the main ones set and the others commented out, for the user to edit.
+//
+// Parameters:
+// - flags: the flag set.
+// - filePath: the path of the configuration file.
+// - force: whether an existing file is overwritten.
+//
+// Returns:
+// - error: an error if the file exists without force, or cannot be written.
+func WriteConfigScaffold(flags *flag.FlagSet, filePath string, force bool) error {
+	if _, err := os.Stat(filePath); err == nil && !force {
+		return fmt.Errorf("%s already exists, use -force to overwrite it", filePath)
+	}
+	var sb strings.Builder
+	sb.WriteString("# mdtocgen configuration, the keys are the names of the flags.\n")
+	sb.WriteString("# The flags given on the command line override these settings.\n")
+	flags.VisitAll(func(f *flag.Flag) {
+		if configOnlyFlags[f.Name] {
+			return
+		}
+		sb.WriteString("\n# " + f.Usage + "\n")
+		if !scaffoldFlags[f.Name] {
+			sb.WriteString("# ")
+		}
+		sb.WriteString(f.Name + ": " + configScalar(f) + "\n")
+	})
+	return os.WriteFile(filePath, []byte(sb.String()), 0644)
+}
+
+// configScalar returns the default value of a flag as a YAML scalar, plain for the booleans and
+// the numbers. The custom flags without a default, such as the repeatable `-http-header`, are
+// written as an empty list, which sets nothing once uncommented, as they reject an empty value.
+func configScalar(f *flag.Flag) string {
+	if _, ok := f.Value.(flag.Getter); !ok && f.DefValue == "" {
+		return "[]"
+	}
+	if b, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && b.IsBoolFlag() {
+		return f.DefValue
+	}
+	if _, err := strconv.ParseFloat(f.DefValue, 64); err == nil {
+		return f.DefValue
+	}
+	return YAMLScalar(f.DefValue)
+}
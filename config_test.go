@@ -0,0 +1,127 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// newConfigFlags returns a flag set with the kinds of flags of the command.
+func newConfigFlags() (*flag.FlagSet, *string, *bool, *int, *HeaderFlag) {
+	flags := flag.NewFlagSet("mdtocgen", flag.ContinueOnError)
+	format := flags.String("format", "markdown", "Output format")
+	asc := flags.Bool("asc", true, "Sort in ascending order")
+	depth := flags.Int("max-depth", 0, "Maximum depth")
+	flags.String("sort", "name", "Sort key")
+	var headers HeaderFlag
+	flags.Var(&headers, "http-header", "Header sent with the HTTP requests, may be repeated")
+	flags.String("config", "", "Configuration file")
+	return flags, format, asc, depth, &headers
+}
+
+func TestApplyConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		args    []string
+		format  string
+		depth   int
+		headers []string
+		wantErr string
+	}{
+		{"empty", "", nil, "markdown", 0, nil, ""},
+		{"scalars", "format: html\nmax-depth: 2\n", nil, "html", 2, nil, ""},
+		{"command line wins", "format: html\n", []string{"-format", "opml"}, "opml", 0, nil, ""},
+		{"list", "http-header:\n  - 'A: 1'\n  - 'B: 2'\n", nil, "markdown", 0, []string{"1", "2"}, ""},
+		{"empty list", "http-header: []\n", nil, "markdown", 0, nil, ""},
+		{"unknown", "colour: red\n", nil, "", 0, nil, `unknown setting "colour"`},
+		{"config only", "config: other.yaml\n", nil, "", 0, nil, `unknown setting "config"`},
+		{"invalid", "max-depth: deep\n", nil, "", 0, nil, "max-depth"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), ConfigFileName)
+			if err := os.WriteFile(file, []byte(tt.config), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			flags, format, _, depth, headers := newConfigFlags()
+			if err := flags.Parse(tt.args); err != nil {
+				t.Fatal(err)
+			}
+			err := ApplyConfig(flags, file, true)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ApplyConfig() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var gotHeaders []string
+			for _, name := range []string{"A", "B"} {
+				gotHeaders = append(gotHeaders, headers.Header.Values(name)...)
+			}
+			if *format != tt.format || *depth != tt.depth || !reflect.DeepEqual(gotHeaders, tt.headers) {
+				t.Errorf("format = %q, max-depth = %d, headers = %q, want %q, %d, %q", *format, *depth, gotHeaders, tt.format, tt.depth, tt.headers)
+			}
+		})
+	}
+}
+
+func TestApplyConfigMissing(t *testing.T) {
+	flags, _, _, _, _ := newConfigFlags()
+	file := filepath.Join(t.TempDir(), ConfigFileName)
+	if err := ApplyConfig(flags, file, false); err != nil {
+		t.Errorf("ApplyConfig() of a missing optional file = %v, want nil", err)
+	}
+	if err := ApplyConfig(flags, file, true); err == nil {
+		t.Error("ApplyConfig() of a missing required file = nil, want an error")
+	}
+}
+
+func TestWriteConfigScaffold(t *testing.T) {
+	flags, _, _, _, _ := newConfigFlags()
+	file := filepath.Join(t.TempDir(), ConfigFileName)
+	if err := WriteConfigScaffold(flags, file, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteConfigScaffold(flags, file, false); err == nil {
+		t.Error("WriteConfigScaffold() over an existing file = nil, want an error")
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"format: markdown\n", "# sort: name\n", "# asc: true\n", "# max-depth: 0\n", "# http-header: []\n"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("scaffold does not contain %q:\n%s", want, content)
+		}
+	}
+	if strings.Contains(string(content), "config:") {
+		t.Errorf("scaffold sets the config flag:\n%s", content)
+	}
+	if strings.Contains(string(content), "\nsort:") {
+		t.Errorf("scaffold sets the sort flag, which overrides the weight order of -hugo:\n%s", content)
+	}
+
+	// Every setting of the scaffold loads once uncommented
+	var uncommented []string
+	for _, line := range strings.Split(string(content), "\n") {
+		setting := strings.TrimPrefix(line, "# ")
+		if name, _, ok := strings.Cut(setting, ": "); ok && flags.Lookup(name) != nil {
+			line = setting
+		}
+		uncommented = append(uncommented, line)
+	}
+	if err := os.WriteFile(file, []byte(strings.Join(uncommented, "\n")), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fresh, _, _, _, _ := newConfigFlags()
+	if err := ApplyConfig(fresh, file, true); err != nil {
+		t.Errorf("ApplyConfig() of the uncommented scaffold = %v", err)
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"html"
+	"strings"
+)
+
+// CreateConfluence generates the TOC in the Confluence storage format, ready to be pasted in the
+// source editor of a page. The files are `<ac:link>` macros referencing the Confluence page whose
+// title is the title of the file, nested in `<ul>` lists like the HTML format.
+//
+// Parameters:
+// - md: the MDFileInfo object representing the root directory.
+// - opts: the options used to render the TOC.
+//
+// Returns:
+// - string: the generated XHTML.
+func CreateConfluence(md MDFileInfo, opts TocOptions) string {
+	var sb strings.Builder
+	sb.WriteString("<h1>" + html.EscapeString(md.Title) + "</h1>\n")
+	writeHTMLList(&sb, md, 0, opts, func(child MDFileInfo) string {
+		if child.IsDir && child.LinkPath == "" {
+			return html.EscapeString(child.Title)
+		}
+		return ConfluenceLink(child.Title)
+	})
+	return sb.String()
+}
+
+// ConfluenceLink renders an `<ac:link>` macro to the Confluence page with the given title.
+//
+// Parameters:
+// - title: the title of the Confluence page.
+//
+// Returns:
+// - string: the rendered macro.
+func ConfluenceLink(title string) string {
+	return "<ac:link><ri:page ri:content-title=\"" + html.EscapeString(title) + "\" />" +
+		"<ac:plain-text-link-body><![CDATA[" + strings.ReplaceAll(title, "]]>", "]]]]><![CDATA[>") + "]]></ac:plain-text-link-body></ac:link>"
+}
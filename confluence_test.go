@@ -0,0 +1,41 @@
+package main
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestConfluenceLink(t *testing.T) {
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{"Intro", `<ac:link><ri:page ri:content-title="Intro" /><ac:plain-text-link-body><![CDATA[Intro]]></ac:plain-text-link-body></ac:link>`},
+		{`Q&A "quoted"`, `<ac:link><ri:page ri:content-title="Q&amp;A &#34;quoted&#34;" /><ac:plain-text-link-body><![CDATA[Q&A "quoted"]]></ac:plain-text-link-body></ac:link>`},
+		{"a]]>b", `<ac:link><ri:page ri:content-title="a]]&gt;b" /><ac:plain-text-link-body><![CDATA[a]]]]><![CDATA[>b]]></ac:plain-text-link-body></ac:link>`},
+	}
+	for _, tt := range tests {
+		if got := ConfluenceLink(tt.title); got != tt.want {
+			t.Errorf("ConfluenceLink(%q) =\n%s\nwant\n%s", tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestCreateConfluence(t *testing.T) {
+	got := CreateConfluence(sampleDocs(t), testTocOptions())
+	for _, want := range []string{
+		"<h1>Docs</h1>\n<ul>\n  <li>guides\n",
+		"<li>" + ConfluenceLink("Getting Started") + "</li>",
+		"<li>" + ConfluenceLink("Intro") + "</li>",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output does not contain %q:\n%s", want, got)
+		}
+	}
+	// The storage format is XHTML, the namespaces are declared by Confluence
+	doc := "<root xmlns:ac=\"ac\" xmlns:ri=\"ri\">" + got + "</root>"
+	if err := xml.Unmarshal([]byte(doc), new(struct{})); err != nil {
+		t.Errorf("the output is not well-formed: %v\n%s", err, got)
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// LoadExpectedTopics reads the planned outline checked by CreateCoverage, one topic per line.
+// A topic is either the path of a file relative to the root directory, with or without its `.md`
+// extension, or its title. Blank lines and the lines starting with `#` are skipped.
+//
+// Parameters:
+// - filePath: the path of the file listing the expected topics.
+//
+// Returns:
+// - []string: the expected topics.
+// - error: an error if the file could not be read.
+func LoadExpectedTopics(filePath string) ([]string, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	var topics []string
+	for _, line := range SplitLines(content) {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		topics = append(topics, line)
+	}
+	return topics, nil
+}
+
+// CreateCoverage renders a checklist of the expected topics: the topics with a matching file are
+// checked and link to it, the missing ones are unchecked, followed by the count of existing topics.
+//
+// Parameters:
+// - md: the MDFileInfo object representing the root directory.
+// - topics: the expected topics, see LoadExpectedTopics.
+// - opts: the options used to render the TOC.
+//
+// Returns:
+// - string: the rendered report.
+func CreateCoverage(md MDFileInfo, topics []string, opts TocOptions) string {
+	entries := FlattenFiles(md, opts)
+	var sb strings.Builder
+	sb.WriteString("# " + md.Title + " coverage\n\n")
+	found := 0
+	for _, topic := range topics {
+		file, ok := findTopic(entries, topic)
+		if !ok {
+			sb.WriteString("- [ ] " + topic + "\n")
+			continue
+		}
+		found++
+		sb.WriteString("- [x] " + FormatLink(file, opts) + "\n")
+	}
+	sb.WriteString(fmt.Sprintf("\n%d of %d expected topics exist.\n", found, len(topics)))
+	return sb.String()
+}
+
+// findTopic returns the file matching an expected topic by relative path or by title.
+func findTopic(entries []FlatEntry, topic string) (MDFileInfo, bool) {
+	topicPath := strings.TrimPrefix(strings.TrimSuffix(topic, ".md"), "./")
+	for _, entry := range entries {
+		if strings.TrimSuffix(RelPath(entry.File), ".md") == topicPath {
+			return entry.File, true
+		}
+	}
+	for _, entry := range entries {
+		if strings.EqualFold(entry.File.Title, topic) {
+			return entry.File, true
+		}
+	}
+	return MDFileInfo{}, false
+}
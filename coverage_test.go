@@ -0,0 +1,52 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadExpectedTopics(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "topics.txt")
+	if err := os.WriteFile(path, []byte("# Planned outline\nintro\n\n  guides/start.md  \r\nDeployment\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadExpectedTopics(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"intro", "guides/start.md", "Deployment"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadExpectedTopics() = %q, want %q", got, want)
+	}
+	if _, err := LoadExpectedTopics(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("LoadExpectedTopics() of a missing file returned no error")
+	}
+}
+
+func TestCreateCoverage(t *testing.T) {
+	tests := []struct {
+		name   string
+		topics []string
+		want   string
+	}{
+		{"all missing", []string{"Deployment", "faq"}, "# Docs coverage\n\n" +
+			"- [ ] Deployment\n" +
+			"- [ ] faq\n" +
+			"\n0 of 2 expected topics exist.\n"},
+		{"by path and title", []string{"intro", "./guides/start.md", "scaling", "Deployment"}, "# Docs coverage\n\n" +
+			"- [x] [Intro](./intro.md)\n" +
+			"- [x] [Getting Started](./guides/start.md)\n" +
+			"- [x] [Scaling](./guides/advanced/scaling.md)\n" +
+			"- [ ] Deployment\n" +
+			"\n3 of 4 expected topics exist.\n"},
+		{"none expected", nil, "# Docs coverage\n\n\n0 of 0 expected topics exist.\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CreateCoverage(sampleDocs(t), tt.topics, testTocOptions()); got != tt.want {
+				t.Errorf("CreateCoverage() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// PrintTree writes a dump of the MDFileInfo tree to w, one node per line indented by its level,
+// with the level, kind, path and resolved title of every node. It is meant to debug which files
+// were discovered and how they were titled.
+//
+// Parameters:
+// - w: the writer the dump is written to, usually os.Stderr.
+// - md: the MDFileInfo object representing the root directory.
+func PrintTree(w io.Writer, md MDFileInfo) {
+	name := md.Name
+	if md.Level == 0 {
+		name = "."
+	}
+	kind := "file"
+	if md.IsDir {
+		kind = "dir"
+	}
+	fmt.Fprintf(w, "%s%s level=%d %s path=%s title=%q\n", strings.Repeat("  ", md.Level), name, md.Level, kind, md.Path, md.Title)
+	for _, key := range SortedChildKeys(md, TocOptions{SortAsc: true}) {
+		PrintTree(w, md.Children[key])
+	}
+}
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPrintTree(t *testing.T) {
+	var buf bytes.Buffer
+	PrintTree(&buf, sampleDocs(t))
+	want := `. level=0 dir path=. title="Docs"
+  guides level=1 dir path=./guides title="guides"
+    advanced level=2 dir path=./guides/advanced title="advanced"
+      scaling.md level=3 file path=./guides/advanced/scaling.md title="Scaling"
+    start.md level=2 file path=./guides/start.md title="Getting Started"
+  intro.md level=1 file path=./intro.md title="Intro"
+`
+	if got := buf.String(); got != want {
+		t.Errorf("PrintTree() =\n%s\nwant\n%s", got, want)
+	}
+}
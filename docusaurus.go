@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// docusaurusNumberPrefixRegex matches the number prefixes Docusaurus removes from the segments of
+// the doc IDs by default, e.g. `01-` in `01-intro.md`, capturing the rest of the segment.
+var docusaurusNumberPrefixRegex = regexp.MustCompile(`^\d+\s*[-_.]+\s*([^-_.\s].*)$`)
+
+// docusaurusCategory is a category item of a Docusaurus sidebar, the docs are plain doc IDs.
+type docusaurusCategory struct {
+	Type  string          `json:"type"`
+	Label string          `json:"label"`
+	Link  *docusaurusLink `json:"link,omitempty"`
+	Items []interface{}   `json:"items"`
+}
+
+// docusaurusLink is the doc a category links to.
+type docusaurusLink struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+// CreateDocusaurusSidebar generates a `sidebars.js` defining a `docs` sidebar: every file is
+// a doc item, its doc ID, see DocusaurusID, and every directory a category nesting its children,
+// linked to its index page when it has a LinkPath.
+//
+// Parameters:
+// - md: the MDFileInfo object representing the root directory, which must be the docs directory.
+// - opts: the options used to render the TOC.
+//
+// Returns:
+// - string: the generated JavaScript.
+func CreateDocusaurusSidebar(md MDFileInfo, opts TocOptions) string {
+	sidebar := map[string][]interface{}{"docs": docusaurusItems(md, opts)}
+	content, _ := json.MarshalIndent(sidebar, "", opts.Indent)
+	return "module.exports = " + string(content) + ";\n"
+}
+
+// docusaurusItems returns the sidebar items of the children of a directory.
+func docusaurusItems(md MDFileInfo, opts TocOptions) []interface{} {
+	items := make([]interface{}, 0, len(md.Children))
+	for _, key := range SortedChildKeys(md, opts) {
+		child := md.Children[key]
+		if !child.IsDir {
+			items = append(items, DocusaurusID(child))
+			continue
+		}
+		category := docusaurusCategory{
+			Type:  "category",
+			Label: child.Title,
+			Items: docusaurusItems(child, opts),
+		}
+		if child.LinkPath != "" {
+			category.Link = &docusaurusLink{Type: "doc", ID: DocusaurusID(MDFileInfo{Path: child.LinkPath})}
+		}
+		items = append(items, category)
+	}
+	return items
+}
+
+// DocusaurusID returns the doc ID Docusaurus gives to a file: its path relative to the docs
+// directory without the extension nor the number prefixes of its segments, with the last segment
+// replaced by the `id` field of its frontmatter if it has one, e.g. `guides/start` for
+// `01-guides/start.md`.
+func DocusaurusID(md MDFileInfo) string {
+	relPath := RelPath(md)
+	segments := strings.Split(strings.TrimSuffix(relPath, path.Ext(relPath)), "/")
+	for i, segment := range segments {
+		if match := docusaurusNumberPrefixRegex.FindStringSubmatch(segment); match != nil {
+			segments[i] = match[1]
+		}
+	}
+	id := strings.Join(segments, "/")
+	if custom := md.Frontmatter["id"]; custom != "" {
+		if dir := path.Dir(id); dir != "." {
+			return dir + "/" + custom
+		}
+		return custom
+	}
+	return id
+}
@@ -0,0 +1,69 @@
+package main
+
+import "testing"
+
+func TestDocusaurusID(t *testing.T) {
+	tests := []struct {
+		name string
+		md   MDFileInfo
+		want string
+	}{
+		{"root", MDFileInfo{Path: "./intro.md"}, "intro"},
+		{"nested", MDFileInfo{Path: "./guides/start.md"}, "guides/start"},
+		{"number prefixes", MDFileInfo{Path: "./01-guides/2_start.md"}, "guides/start"},
+		{"number only", MDFileInfo{Path: "./2024.md"}, "2024"},
+		{"custom id", MDFileInfo{Path: "./guides/start.md", Frontmatter: map[string]string{"id": "begin"}}, "guides/begin"},
+		{"custom id at root", MDFileInfo{Path: "./intro.md", Frontmatter: map[string]string{"id": "welcome"}}, "welcome"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DocusaurusID(tt.md); got != tt.want {
+				t.Errorf("DocusaurusID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateDocusaurusSidebar(t *testing.T) {
+	want := "module.exports = {\n" +
+		"  \"docs\": [\n" +
+		"    {\n" +
+		"      \"type\": \"category\",\n" +
+		"      \"label\": \"guides\",\n" +
+		"      \"items\": [\n" +
+		"        {\n" +
+		"          \"type\": \"category\",\n" +
+		"          \"label\": \"advanced\",\n" +
+		"          \"items\": [\n" +
+		"            \"guides/advanced/scaling\"\n" +
+		"          ]\n" +
+		"        },\n" +
+		"        \"guides/start\"\n" +
+		"      ]\n" +
+		"    },\n" +
+		"    \"intro\"\n" +
+		"  ]\n" +
+		"};\n"
+	if got := CreateDocusaurusSidebar(sampleDocs(t), testTocOptions()); got != want {
+		t.Errorf("CreateDocusaurusSidebar() =\n%s\nwant\n%s", got, want)
+	}
+	md := MDFileInfo{IsDir: true, Children: map[string]MDFileInfo{
+		"guides": {Name: "guides", Title: "Guides", IsDir: true, LinkPath: "./guides/index.md", Children: map[string]MDFileInfo{}},
+	}}
+	want = "module.exports = {\n" +
+		"  \"docs\": [\n" +
+		"    {\n" +
+		"      \"type\": \"category\",\n" +
+		"      \"label\": \"Guides\",\n" +
+		"      \"link\": {\n" +
+		"        \"type\": \"doc\",\n" +
+		"        \"id\": \"guides/index\"\n" +
+		"      },\n" +
+		"      \"items\": []\n" +
+		"    }\n" +
+		"  ]\n" +
+		"};\n"
+	if got := CreateDocusaurusSidebar(md, testTocOptions()); got != want {
+		t.Errorf("CreateDocusaurusSidebar() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// dotLabelReplacer escapes the characters of a double-quoted Graphviz string.
+var dotLabelReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// CreateDOT generates the Graphviz DOT graph of the tree: every directory and file is a node
+// labeled with its title, with an edge from its parent. The nodes are numbered in rendering order,
+// `n0` being the root, so the IDs are unique whatever the titles.
+//
+// Parameters:
+// - md: the MDFileInfo object representing the root directory.
+// - opts: the options used to render the TOC.
+//
+// Returns:
+// - string: the generated DOT.
+func CreateDOT(md MDFileInfo, opts TocOptions) string {
+	var sb strings.Builder
+	sb.WriteString("digraph docs {\n")
+	sb.WriteString(opts.Indent + "rankdir=LR;\n")
+	next := 0
+	var walk func(node MDFileInfo) string
+	walk = func(node MDFileInfo) string {
+		id := fmt.Sprintf("n%d", next)
+		next++
+		shape := "note"
+		if node.IsDir {
+			shape = "folder"
+		}
+		sb.WriteString(fmt.Sprintf("%s%s [label=\"%s\", shape=%s];\n", opts.Indent, id, dotLabelReplacer.Replace(node.Title), shape))
+		for _, key := range SortedChildKeys(node, opts) {
+			childID := walk(node.Children[key])
+			sb.WriteString(opts.Indent + id + " -> " + childID + ";\n")
+		}
+		return id
+	}
+	walk(md)
+	sb.WriteString("}\n")
+	return sb.String()
+}
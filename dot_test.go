@@ -0,0 +1,41 @@
+package main
+
+import "testing"
+
+func TestCreateDOT(t *testing.T) {
+	want := "digraph docs {\n" +
+		"  rankdir=LR;\n" +
+		"  n0 [label=\"Docs\", shape=folder];\n" +
+		"  n1 [label=\"guides\", shape=folder];\n" +
+		"  n2 [label=\"advanced\", shape=folder];\n" +
+		"  n3 [label=\"Scaling\", shape=note];\n" +
+		"  n2 -> n3;\n" +
+		"  n1 -> n2;\n" +
+		"  n4 [label=\"Getting Started\", shape=note];\n" +
+		"  n1 -> n4;\n" +
+		"  n0 -> n1;\n" +
+		"  n5 [label=\"Intro\", shape=note];\n" +
+		"  n0 -> n5;\n" +
+		"}\n"
+	if got := CreateDOT(sampleDocs(t), testTocOptions()); got != want {
+		t.Errorf("CreateDOT() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestCreateDOTEscapes(t *testing.T) {
+	md := MDFileInfo{Title: `Say "hi"`, IsDir: true, Children: map[string]MDFileInfo{
+		"a.md": {Name: "a.md", Title: `C:\docs`},
+		"b.md": {Name: "b.md", Title: `C:\docs`},
+	}}
+	want := "digraph docs {\n" +
+		"  rankdir=LR;\n" +
+		"  n0 [label=\"Say \\\"hi\\\"\", shape=folder];\n" +
+		"  n1 [label=\"C:\\\\docs\", shape=note];\n" +
+		"  n0 -> n1;\n" +
+		"  n2 [label=\"C:\\\\docs\", shape=note];\n" +
+		"  n0 -> n2;\n" +
+		"}\n"
+	if got := CreateDOT(md, testTocOptions()); got != want {
+		t.Errorf("CreateDOT() =\n%s\nwant\n%s", got, want)
+	}
+}
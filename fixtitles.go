@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// FixTitles inserts an H1 derived from the file name, see TitleFromFileName, at the top of the
+// files of the tree which have no ATX, Setext or HTML H1. The H1 is inserted below the
+// frontmatter of the files which have one. Every fixed file is reported to w.
+//
+// Parameters:
+// - dirPath: the directory the tree was listed from.
+// - md: the MDFileInfo object representing the root directory.
+// - acronyms: the words kept in upper case in the titles, see TitleFromFileName.
+// - dryRun: whether the files are only reported, without being modified.
+// - w: the writer the fixed files are reported to, usually os.Stderr.
+//
+// Returns:
+// - int: the number of files lacking an H1.
+// - error: an error if a file could not be read or written.
+func FixTitles(dirPath string, md MDFileInfo, acronyms []string, dryRun bool, w io.Writer) (int, error) {
+	fixed := 0
+	for _, entry := range FlattenFiles(md, TocOptions{SortAsc: true}) {
+		filePath := filepath.Join(dirPath, filepath.FromSlash(RelPath(entry.File)))
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return fixed, err
+		}
+		title := TitleFromFileName(entry.File.Name, acronyms)
+		updated, ok := InsertH1(content, title)
+		if !ok {
+			continue
+		}
+		fixed++
+		if dryRun {
+			fmt.Fprintf(w, "%s: would insert # %s\n", filePath, title)
+			continue
+		}
+		if err := os.WriteFile(filePath, updated, 0644); err != nil {
+			return fixed, err
+		}
+		fmt.Fprintf(w, "%s: inserted # %s\n", filePath, title)
+	}
+	return fixed, nil
+}
+
+// InsertH1 inserts `# title` at the top of the content, below its frontmatter if it has one,
+// unless the content already has an H1. The line endings of the content are kept.
+//
+// Parameters:
+// - content: the content of the Markdown file.
+// - title: the title of the inserted H1.
+//
+// Returns:
+// - []byte: the updated content.
+// - bool: false if the content already has an H1 and is returned as is.
+func InsertH1(content []byte, title string) ([]byte, bool) {
+	lines := SplitLines(content)
+	if _, ok := H1Title(lines); ok {
+		return content, false
+	}
+	if _, ok := SetextTitle(lines); ok {
+		return content, false
+	}
+	if _, ok := HTMLTitle(lines); ok {
+		return content, false
+	}
+	newline := "\n"
+	if bytes.Contains(content, []byte("\r\n")) {
+		newline = "\r\n"
+	}
+	_, body := SplitFrontmatter(lines)
+	head := lines[:len(lines)-len(body)]
+	updated := append(append([]string{}, head...), "# "+title)
+	if len(body) > 0 {
+		if strings.TrimSpace(body[0]) != "" {
+			updated = append(updated, "")
+		}
+		updated = append(updated, body...)
+	}
+	return []byte(strings.Join(updated, newline) + newline), true
+}
+
+// TitleFromFileName derives a title from a file name, e.g. `Getting started` for `getting-started.md`:
+// the extension is removed, dashes and underscores become spaces and the first letter is capitalized.
+// The words matching one of the acronyms are spelled like the acronym, e.g. `API guide` for `api-guide.md`.
+//
+// Parameters:
+// - name: the name of the file.
+// - acronyms: the words kept in upper case, e.g. API, URL or HTTP.
+//
+// Returns:
+// - string: the title.
+func TitleFromFileName(name string, acronyms []string) string {
+	words := splitNameWords(strings.TrimSuffix(name, filepath.Ext(name)))
+	for i, word := range words {
+		if acronym, ok := matchAcronym(word, acronyms); ok {
+			words[i] = acronym
+		} else if i == 0 {
+			r, size := utf8.DecodeRuneInString(word)
+			words[i] = string(unicode.ToUpper(r)) + word[size:]
+		}
+	}
+	return strings.Join(words, " ")
+}
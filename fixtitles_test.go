@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestInsertH1(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		wantOK  bool
+	}{
+		{"plain", "Some text.\n", "# Title\n\nSome text.\n", true},
+		{"empty", "", "# Title\n", true},
+		{"blank first line", "\nSome text.\n", "# Title\n\nSome text.\n", true},
+		{"frontmatter", "---\ndraft: false\n---\nSome text.\n", "---\ndraft: false\n---\n# Title\n\nSome text.\n", true},
+		{"crlf", "---\r\na: b\r\n---\r\nText\r\n", "---\r\na: b\r\n---\r\n# Title\r\n\r\nText\r\n", true},
+		{"only an h2", "## Part\n", "# Title\n\n## Part\n", true},
+		{"atx h1", "# Existing\n", "# Existing\n", false},
+		{"setext h1", "Existing\n========\n", "Existing\n========\n", false},
+		{"html h1", "<h1>Existing</h1>\n", "<h1>Existing</h1>\n", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := InsertH1([]byte(tt.content), "Title")
+			if ok != tt.wantOK || string(got) != tt.want {
+				t.Errorf("InsertH1() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestTitleFromFileName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"getting-started.md", "Getting started"},
+		{"rest_api.md", "Rest api"},
+		{"intro.md", "Intro"},
+		{"élan.md", "Élan"},
+	}
+	for _, tt := range tests {
+		if got := TitleFromFileName(tt.name, nil); got != tt.want {
+			t.Errorf("TitleFromFileName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFixTitles(t *testing.T) {
+	files := map[string]string{
+		"intro.md":                  "# Intro\n",
+		"guides/getting-started.md": "---\ntitle: x\n---\nText\n",
+		"notes.md":                  "Notes\n",
+	}
+	tests := []struct {
+		name   string
+		dryRun bool
+		want   map[string]string
+		report string
+	}{
+		{"dry run", true, files, "would insert # Getting started\n"},
+		{"fix", false, map[string]string{
+			"intro.md":                  "# Intro\n",
+			"guides/getting-started.md": "---\ntitle: x\n---\n# Getting started\n\nText\n",
+			"notes.md":                  "# Notes\n\nNotes\n",
+		}, "inserted # Notes\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeTree(t, files)
+			var report bytes.Buffer
+			fixed, err := FixTitles(dir, listTree(t, dir, testListOptions()), nil, tt.dryRun, &report)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fixed != 2 {
+				t.Errorf("FixTitles() = %d, want 2", fixed)
+			}
+			if !bytes.Contains(report.Bytes(), []byte(tt.report)) {
+				t.Errorf("report does not contain %q:\n%s", tt.report, report.String())
+			}
+			for name, want := range tt.want {
+				content, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(content) != want {
+					t.Errorf("%s = %q, want %q", name, content, want)
+				}
+			}
+		})
+	}
+}
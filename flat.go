@@ -0,0 +1,170 @@
+package main
+
+import (
+	"net/url"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// DefaultFlatEntryFormat is the template of the entries of the flat format.
+const DefaultFlatEntryFormat = "- {{.Link}}{{with .Section}} — {{.}}{{end}}"
+
+// DefaultBreadcrumbsEntryFormat is the template of the entries of the breadcrumbs format.
+const DefaultBreadcrumbsEntryFormat = "- {{.Breadcrumbs}}"
+
+// EntryData holds the fields available to the `-entry-format` template of the flat and breadcrumbs formats.
+type EntryData struct {
+	Title       string // the title of the file
+	Path        string // the path of the file relative to the root directory
+	Link        string // the link to the file, in the link style of the options
+	Section     string // the titles of the directories leading to the file, joined with ` / `
+	Breadcrumbs string // the links to the directories leading to the file and to the file, joined with ` / `
+	Depth       int    // the number of directories leading to the file
+}
+
+// ParseEntryFormat parses an `-entry-format` template and checks it against an empty EntryData,
+// so that a reference to an unknown field is reported before anything is rendered.
+//
+// Parameters:
+// - format: the text/template source.
+//
+// Returns:
+// - *template.Template: the parsed template.
+// - error: an error if the template is invalid.
+func ParseEntryFormat(format string) (*template.Template, error) {
+	tmpl, err := template.New("entry").Parse(format)
+	if err != nil {
+		return nil, err
+	}
+	if err := tmpl.Execute(new(strings.Builder), EntryData{}); err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+// CreateFlatList generates one line per file, rendered with the opts.EntryFormat template,
+// DefaultFlatEntryFormat if it is nil.
+//
+// Parameters:
+// - md: the MDFileInfo object representing the root directory.
+// - opts: the options used to render the TOC.
+//
+// Returns:
+// - string: the generated list.
+func CreateFlatList(md MDFileInfo, opts TocOptions) string {
+	tmpl := opts.EntryFormat
+	if tmpl == nil {
+		tmpl = template.Must(template.New("entry").Parse(DefaultFlatEntryFormat))
+	}
+	var sb strings.Builder
+	sb.WriteString(RootHeading(md, opts) + "\n")
+	for _, entry := range FlattenFiles(md, opts) {
+		writeEntry(&sb, tmpl, entry.Data(opts))
+	}
+	return sb.String()
+}
+
+// InlineSeparator separates the links of the inline format.
+const InlineSeparator = " | "
+
+// CreateInlineNav generates a single line of links to the files, in rendering order and separated
+// by InlineSeparator, e.g. `[A](a.md) | [B](b.md)`, to use as a navigation bar. The directories
+// are left out.
+//
+// Parameters:
+// - md: the MDFileInfo object representing the root directory.
+// - opts: the options used to render the TOC.
+//
+// Returns:
+// - string: the generated line.
+func CreateInlineNav(md MDFileInfo, opts TocOptions) string {
+	entries := FlattenFiles(md, opts)
+	links := make([]string, len(entries))
+	for i, entry := range entries {
+		links[i] = FormatLink(entry.File, opts)
+	}
+	return strings.Join(links, InlineSeparator) + "\n"
+}
+
+// writeEntry writes an entry rendered with the template, followed by a newline. The template was
+// checked by ParseEntryFormat, so it cannot fail on the fields of EntryData.
+func writeEntry(sb *strings.Builder, tmpl *template.Template, data EntryData) {
+	_ = tmpl.Execute(sb, data)
+	sb.WriteString("\n")
+}
+
+// FlatEntry is a file of the tree along with the directories leading to it.
+type FlatEntry struct {
+	File      MDFileInfo
+	Ancestors []MDFileInfo // the directories from the top-level section down to the parent of File
+}
+
+// FlattenFiles lists the files of the tree in rendering order, depth first.
+//
+// Parameters:
+// - md: the MDFileInfo object representing the root directory.
+// - opts: the options used to render the TOC.
+//
+// Returns:
+// - []FlatEntry: the files of the tree.
+func FlattenFiles(md MDFileInfo, opts TocOptions) []FlatEntry {
+	var entries []FlatEntry
+	var walk func(node MDFileInfo, ancestors []MDFileInfo)
+	walk = func(node MDFileInfo, ancestors []MDFileInfo) {
+		for _, key := range SortedChildKeys(node, opts) {
+			child := node.Children[key]
+			if !child.IsDir {
+				entries = append(entries, FlatEntry{File: child, Ancestors: ancestors})
+				continue
+			}
+			walk(child, append(ancestors[:len(ancestors):len(ancestors)], child))
+		}
+	}
+	walk(md, nil)
+	return entries
+}
+
+// Section returns the titles of the ancestors of the entry joined with ` / `,
+// or an empty string for the files at the root.
+func (e FlatEntry) Section() string {
+	titles := make([]string, len(e.Ancestors))
+	for i, ancestor := range e.Ancestors {
+		titles[i] = ancestor.Title
+	}
+	return strings.Join(titles, " / ")
+}
+
+// Data returns the fields of the entry available to the `-entry-format` template.
+func (e FlatEntry) Data(opts TocOptions) EntryData {
+	crumbs := make([]string, 0, len(e.Ancestors)+1)
+	for _, dir := range e.Ancestors {
+		crumbs = append(crumbs, DirLink(dir, opts))
+	}
+	link := FormatLink(e.File, opts)
+	crumbs = append(crumbs, link)
+	return EntryData{
+		Title:       e.File.Title,
+		Path:        RelPath(e.File),
+		Link:        link,
+		Section:     e.Section(),
+		Breadcrumbs: strings.Join(crumbs, " / "),
+		Depth:       len(e.Ancestors),
+	}
+}
+
+// RelPath returns the unescaped, slash-separated path of md relative to the root directory,
+// without the leading `./`.
+//
+// Parameters:
+// - md: the MDFileInfo object representing the file or directory.
+//
+// Returns:
+// - string: the relative path.
+func RelPath(md MDFileInfo) string {
+	path, err := url.PathUnescape(md.Path)
+	if err != nil {
+		path = md.Path
+	}
+	return strings.TrimPrefix(filepath.ToSlash(path), "./")
+}
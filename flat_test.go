@@ -0,0 +1,78 @@
+package main
+
+import "testing"
+
+func TestParseEntryFormat(t *testing.T) {
+	tests := []struct {
+		format  string
+		wantErr bool
+	}{
+		{DefaultFlatEntryFormat, false},
+		{DefaultBreadcrumbsEntryFormat, false},
+		{"{{.Title}} — {{.Path}}", false},
+		{"{{.Title}", true},
+		{"{{.Unknown}}", true},
+	}
+	for _, tt := range tests {
+		if _, err := ParseEntryFormat(tt.format); (err != nil) != tt.wantErr {
+			t.Errorf("ParseEntryFormat(%q) error = %v, wantErr %v", tt.format, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCreateFlatList(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		want   string
+	}{
+		{"default", "", "# Docs\n\n" +
+			"- [Scaling](./guides/advanced/scaling.md) — guides / advanced\n" +
+			"- [Getting Started](./guides/start.md) — guides\n" +
+			"- [Intro](./intro.md)\n"},
+		{"custom", "* {{.Title}} — {{.Path}} ({{.Depth}})", "# Docs\n\n" +
+			"* Scaling — guides/advanced/scaling.md (2)\n" +
+			"* Getting Started — guides/start.md (1)\n" +
+			"* Intro — intro.md (0)\n"},
+		{"section", "{{.Section}}: {{.Link}}", "# Docs\n\n" +
+			"guides / advanced: [Scaling](./guides/advanced/scaling.md)\n" +
+			"guides: [Getting Started](./guides/start.md)\n" +
+			": [Intro](./intro.md)\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := testTocOptions()
+			if tt.format != "" {
+				tmpl, err := ParseEntryFormat(tt.format)
+				if err != nil {
+					t.Fatal(err)
+				}
+				opts.EntryFormat = tmpl
+			}
+			if got := CreateFlatList(sampleDocs(t), opts); got != tt.want {
+				t.Errorf("CreateFlatList() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateInlineNav(t *testing.T) {
+	tests := []struct {
+		name string
+		md   MDFileInfo
+		want string
+	}{
+		{"tree", sampleDocs(t), "[Scaling](./guides/advanced/scaling.md) | [Getting Started](./guides/start.md) | [Intro](./intro.md)\n"},
+		{"single", MDFileInfo{IsDir: true, Children: map[string]MDFileInfo{
+			"a.md": {Name: "a.md", Title: "A [1]", Path: "./a.md"},
+		}}, "[A \\[1\\]](./a.md)\n"},
+		{"empty", MDFileInfo{IsDir: true}, "\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CreateInlineNav(tt.md, testTocOptions()); got != tt.want {
+				t.Errorf("CreateInlineNav() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+)
+
+// DefaultGitHubServer is the server of the GitHub links when GITHUB_SERVER_URL is not set.
+const DefaultGitHubServer = "https://github.com"
+
+// GitHubRepo identifies the revision of a GitHub repository the links of the github-comment
+// format point to.
+type GitHubRepo struct {
+	Server string // the URL of the server, e.g. https://github.com
+	Name   string // the repository, as owner/repo
+	Ref    string // the branch, tag or commit, e.g. main
+	Path   string // the directory of the tree in the repository, e.g. docs, empty for its root
+}
+
+// GitHubRepoFromEnv returns the repository described by the variables GitHub Actions sets,
+// GITHUB_SERVER_URL, GITHUB_REPOSITORY and GITHUB_SHA, for the values not given by the flags.
+//
+// Parameters:
+// - name: the repository given by the flags, as owner/repo, empty to use GITHUB_REPOSITORY.
+// - ref: the ref given by the flags, empty to use GITHUB_SHA, or HEAD when it is not set.
+//
+// Returns:
+// - GitHubRepo: the repository.
+func GitHubRepoFromEnv(name, ref string) GitHubRepo {
+	repo := GitHubRepo{
+		Server: strings.TrimSuffix(os.Getenv("GITHUB_SERVER_URL"), "/"),
+		Name:   name,
+		Ref:    ref,
+	}
+	if repo.Server == "" {
+		repo.Server = DefaultGitHubServer
+	}
+	if repo.Name == "" {
+		repo.Name = os.Getenv("GITHUB_REPOSITORY")
+	}
+	if repo.Ref == "" {
+		repo.Ref = os.Getenv("GITHUB_SHA")
+	}
+	if repo.Ref == "" {
+		repo.Ref = "HEAD"
+	}
+	return repo
+}
+
+// GitHubURL returns the absolute URL of a file, `.../blob/{ref}/{path}`, or of a directory,
+// `.../tree/{ref}/{path}`, in the repository, so that the link resolves in an issue or a comment.
+//
+// Parameters:
+// - md: the MDFileInfo object representing the file or directory.
+// - repo: the repository.
+//
+// Returns:
+// - string: the absolute URL.
+func GitHubURL(md MDFileInfo, repo GitHubRepo) string {
+	kind := "blob"
+	if md.IsDir {
+		kind = "tree"
+	}
+	target := repo.Server + "/" + repo.Name + "/" + kind + "/" + url.PathEscape(repo.Ref)
+	if repoPath := path.Join(repo.Path, RelPath(md)); repoPath != "" && repoPath != "." {
+		target += "/" + (&url.URL{Path: repoPath}).EscapedPath()
+	}
+	return target
+}
+
+// GitPrefix returns the path of a directory relative to the top-level directory of its git
+// working tree, e.g. `docs` for the docs directory of a repository, so that the links of the
+// github-comment format point into the directory. It is empty for the top-level directory, or
+// when git is not available or the directory is not in a working tree.
+//
+// Parameters:
+// - dir: the directory.
+//
+// Returns:
+// - string: the slash-separated path of the directory in the repository.
+func GitPrefix(dir string) string {
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "--show-prefix").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSuffix(strings.TrimSpace(string(out)), "/")
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestGitHubRepoFromEnv(t *testing.T) {
+	tests := []struct {
+		name      string
+		env       map[string]string
+		repo, ref string
+		want      GitHubRepo
+	}{
+		{"defaults", nil, "", "", GitHubRepo{Server: DefaultGitHubServer, Ref: "HEAD"}},
+		{"actions", map[string]string{"GITHUB_SERVER_URL": "https://ghe.example.com/", "GITHUB_REPOSITORY": "acme/docs", "GITHUB_SHA": "abc123"}, "", "",
+			GitHubRepo{Server: "https://ghe.example.com", Name: "acme/docs", Ref: "abc123"}},
+		{"flags first", map[string]string{"GITHUB_REPOSITORY": "acme/docs", "GITHUB_SHA": "abc123"}, "me/fork", "main",
+			GitHubRepo{Server: DefaultGitHubServer, Name: "me/fork", Ref: "main"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"GITHUB_SERVER_URL", "GITHUB_REPOSITORY", "GITHUB_SHA"} {
+				t.Setenv(key, tt.env[key])
+			}
+			if got := GitHubRepoFromEnv(tt.repo, tt.ref); got != tt.want {
+				t.Errorf("GitHubRepoFromEnv() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGitHubURL(t *testing.T) {
+	repo := GitHubRepo{Server: DefaultGitHubServer, Name: "acme/docs", Ref: "main"}
+	tests := []struct {
+		name string
+		md   MDFileInfo
+		path string
+		ref  string
+		want string
+	}{
+		{"file", MDFileInfo{Path: "./guides/start.md"}, "", "", "https://github.com/acme/docs/blob/main/guides/start.md"},
+		{"directory", MDFileInfo{IsDir: true, Path: "./guides"}, "", "", "https://github.com/acme/docs/tree/main/guides"},
+		{"root", MDFileInfo{IsDir: true, Path: "."}, "", "", "https://github.com/acme/docs/tree/main"},
+		{"subdirectory", MDFileInfo{Path: "./start.md"}, "docs", "", "https://github.com/acme/docs/blob/main/docs/start.md"},
+		{"subdirectory root", MDFileInfo{IsDir: true, Path: "."}, "docs", "", "https://github.com/acme/docs/tree/main/docs"},
+		{"escaped", MDFileInfo{Path: "./My%20Notes%231.md"}, "", "release/1.0", "https://github.com/acme/docs/blob/release%2F1.0/My%20Notes%231.md"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := repo
+			repo.Path = tt.path
+			if tt.ref != "" {
+				repo.Ref = tt.ref
+			}
+			if got := GitHubURL(tt.md, repo); got != tt.want {
+				t.Errorf("GitHubURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGitPrefix(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	top := t.TempDir()
+	if out, err := exec.Command("git", "init", "-q", top).CombinedOutput(); err != nil {
+		t.Skipf("git init: %v: %s", err, out)
+	}
+	docs := filepath.Join(top, "docs", "api")
+	if err := os.MkdirAll(docs, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		dir  string
+		want string
+	}{
+		{top, ""},
+		{docs, "docs/api"},
+		{t.TempDir(), ""},
+	}
+	for _, tt := range tests {
+		if got := GitPrefix(tt.dir); got != tt.want {
+			t.Errorf("GitPrefix(%q) = %q, want %q", tt.dir, got, tt.want)
+		}
+	}
+}
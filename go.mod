@@ -1,3 +1,8 @@
 module github.com/ducminhgd/mdtocgen
 
 go 1.20
+
+require (
+	golang.org/x/sync v0.10.0
+	gopkg.in/yaml.v3 v3.0.1
+)
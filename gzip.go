@@ -0,0 +1,44 @@
+package main
+
+import (
+	"compress/gzip"
+	"os"
+	"strings"
+)
+
+// GzipExt is the extension added to the output file compressed with `-gzip`.
+const GzipExt = ".gz"
+
+// GzipPath returns the path of the compressed output file: the output file with GzipExt
+// appended, unless it already ends with it.
+func GzipPath(filePath string) string {
+	if strings.HasSuffix(filePath, GzipExt) {
+		return filePath
+	}
+	return filePath + GzipExt
+}
+
+// WriteGzipFile writes the content compressed with gzip to the given file, replacing it.
+//
+// Parameters:
+// - filePath: the path of the file.
+// - content: the uncompressed content.
+//
+// Returns:
+// - error: an error if the file could not be written.
+func WriteGzipFile(filePath string, content []byte) error {
+	f, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(f)
+	if _, err := zw.Write(content); err != nil {
+		f.Close()
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// readGzipFile returns the uncompressed content of a gzip file.
+func readGzipFile(t *testing.T, filePath string) string {
+	t.Helper()
+	f, err := os.Open(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+func TestGzipPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"TOC.md", "TOC.md.gz"},
+		{"TOC.md.gz", "TOC.md.gz"},
+		{"toc", "toc.gz"},
+	}
+	for _, tt := range tests {
+		if got := GzipPath(tt.path); got != tt.want {
+			t.Errorf("GzipPath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestWriteGzipFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "TOC.md.gz")
+	want := "# Docs\n\n- [Intro](intro.md)\n"
+	if err := WriteGzipFile(path, []byte(want)); err != nil {
+		t.Fatal(err)
+	}
+	if got := readGzipFile(t, path); got != want {
+		t.Errorf("WriteGzipFile() wrote %q, want %q", got, want)
+	}
+	if err := WriteGzipFile(filepath.Join(t.TempDir(), "missing", "TOC.md.gz"), []byte(want)); err == nil {
+		t.Error("WriteGzipFile() in a missing directory returned no error")
+	}
+}
+
+func TestGzipFlag(t *testing.T) {
+	dir := writeTree(t, map[string]string{"intro.md": "# Intro\n"})
+	want := runMain(t, "-dir", dir, "-t", "Docs")
+	outFile := filepath.Join(t.TempDir(), "TOC.md")
+	runMain(t, "-dir", dir, "-t", "Docs", "-out", outFile, "-gzip")
+	if _, err := os.Stat(outFile); !os.IsNotExist(err) {
+		t.Errorf("the uncompressed %s was written", outFile)
+	}
+	if got := readGzipFile(t, outFile+GzipExt); got != want {
+		t.Errorf("compressed output = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// HeadingProblems returns the problems of the heading hierarchy of a Markdown file: the headings
+// skipping a level, e.g. an H3 right after an H1, and the H1 headers after the first one. The headers
+// are found like CountHeadings does, see SetextLevel, the problems start with the number of their line.
+//
+// Parameters:
+// - lines: the lines of the Markdown file.
+//
+// Returns:
+// - []string: the problems, empty if the hierarchy is well-formed.
+func HeadingProblems(lines []string) []string {
+	var problems []string
+	prose := ProseLines(lines)
+	// The body lines follow the frontmatter
+	offset := len(lines) - len(prose)
+	previous, h1s := 0, 0
+	for i, line := range prose {
+		level, lineNumber := SetextLevel(prose, i), offset+i+1
+		switch {
+		case headingRegex.MatchString(line):
+			level = len(line) - len(strings.TrimLeft(line, "#"))
+		case level > 0:
+			// The text of a Setext header is on the line above
+			lineNumber--
+		default:
+			continue
+		}
+		if level == 1 {
+			if h1s++; h1s > 1 {
+				problems = append(problems, fmt.Sprintf("%d: multiple H1 headers", lineNumber))
+			}
+		}
+		if previous > 0 && level > previous+1 {
+			problems = append(problems, fmt.Sprintf("%d: H%d skips a level after H%d", lineNumber, level, previous))
+		}
+		previous = level
+	}
+	return problems
+}
+
+// LintHeadings checks the heading hierarchy of all the files of the tree, see HeadingProblems,
+// and writes a warning per problem to w, e.g. `guides/start.md:12: H3 skips a level after H1`.
+//
+// Parameters:
+// - dirPath: the directory the tree was listed from.
+// - md: the MDFileInfo object representing the root directory.
+// - w: the writer the warnings are written to, usually os.Stderr.
+//
+// Returns:
+// - int: the number of problems.
+// - error: an error if a file could not be read.
+func LintHeadings(dirPath string, md MDFileInfo, w io.Writer) (int, error) {
+	count := 0
+	for _, entry := range FlattenFiles(md, TocOptions{SortAsc: true}) {
+		relPath := RelPath(entry.File)
+		content, err := os.ReadFile(filepath.Join(dirPath, filepath.FromSlash(relPath)))
+		if err != nil {
+			return count, err
+		}
+		for _, problem := range HeadingProblems(SplitLines(content)) {
+			fmt.Fprintf(w, "%s:%s\n", relPath, problem)
+			count++
+		}
+	}
+	return count, nil
+}
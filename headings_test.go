@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestHeadingProblems(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{"well-formed", "# Title\n\n## Part\n\n### Detail\n\n## Other\n", nil},
+		{"skipped level", "# Title\n\n### Detail\n", []string{"3: H3 skips a level after H1"}},
+		{"multiple h1", "# One\n\n# Two\n", []string{"3: multiple H1 headers"}},
+		{"setext", "Title\n=====\n\nPart\n----\n\n#### Deep\n", []string{"7: H4 skips a level after H2"}},
+		{"setext h1 twice", "# One\n\nTwo\n===\n", []string{"3: multiple H1 headers"}},
+		{"thematic break", "# Title\n\n---\n\n### Detail\n", []string{"5: H3 skips a level after H1"}},
+		{"break after a list", "# Title\n\n- item\n---\n\n### Detail\n", []string{"6: H3 skips a level after H1"}},
+		{"text after a blockquote", "# Title\n\n> quote\n===\n", nil},
+		{"frontmatter", "---\ntitle: x\n---\n# Title\n### Detail\n", []string{"5: H3 skips a level after H1"}},
+		{"code block", "# Title\n\n```\n### not a heading\n```\n", nil},
+		{"starts deep", "### Detail\n", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HeadingProblems(SplitLines([]byte(tt.content))); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("HeadingProblems() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLintHeadings(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"intro.md":        "# Intro\n\n## Part\n",
+		"guides/start.md": "# Start\n\n### Detail\n\n# Again\n",
+	})
+	var warnings bytes.Buffer
+	count, err := LintHeadings(dir, listTree(t, dir, testListOptions()), &warnings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "guides/start.md:3: H3 skips a level after H1\nguides/start.md:5: multiple H1 headers\n"
+	if count != 2 || warnings.String() != want {
+		t.Errorf("LintHeadings() = %d,\n%s\nwant 2,\n%s", count, warnings.String(), want)
+	}
+}
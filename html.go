@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+// htmlSearchInput is the filter box added before the list with the `-search` flag.
+const htmlSearchInput = `<input type="search" placeholder="Filter" aria-label="Filter the table of contents">`
+
+// htmlSearchScript follows the list and hides its `<li>` elements which do not contain the text typed
+// in the filter box. The parents of a matching element stay visible, as their text includes the text
+// of their children.
+const htmlSearchScript = `<script>
+(function () {
+  var list = document.currentScript.previousElementSibling;
+  var input = list.previousElementSibling;
+  input.addEventListener("input", function () {
+    var query = input.value.toLowerCase();
+    list.querySelectorAll("li").forEach(function (li) {
+      li.hidden = query !== "" && li.textContent.toLowerCase().indexOf(query) === -1;
+    });
+  });
+})();
+</script>`
+
+// CreateHTMLTree generates the TOC as nested HTML `<ul>` lists under an `<h1>` title.
+//
+// When opts.Nav is set, the TOC is wrapped in a `<nav aria-label="Table of contents">` element,
+// the lists get `role="list"` so that assistive technologies keep announcing them when the list
+// style is removed, and the link to opts.NavCurrent is marked with `aria-current="page"`.
+// When opts.Search is set, a filter box and a self-contained script hiding the entries which do
+// not match the typed text surround the list.
+//
+// Parameters:
+// - md: the MDFileInfo object representing the root directory.
+// - opts: the options used to render the TOC.
+//
+// Returns:
+// - string: the generated HTML.
+func CreateHTMLTree(md MDFileInfo, opts TocOptions) string {
+	var sb strings.Builder
+	depth := 0
+	if opts.Nav {
+		sb.WriteString("<nav aria-label=\"Table of contents\">\n")
+		depth = 1
+	}
+	indent := strings.Repeat(opts.Indent, depth)
+	sb.WriteString(fmt.Sprintf("%s<h1>%s</h1>\n", indent, html.EscapeString(md.Title)))
+	if opts.TocHeading != "" {
+		sb.WriteString(fmt.Sprintf("%s<h2>%s</h2>\n", indent, html.EscapeString(opts.TocHeading)))
+	}
+	if opts.Search {
+		sb.WriteString(indent + htmlSearchInput + "\n")
+	}
+	writeHTMLList(&sb, md, depth, opts, func(child MDFileInfo) string {
+		return htmlEntry(child, opts)
+	})
+	if opts.Search {
+		sb.WriteString(indent + strings.ReplaceAll(htmlSearchScript, "\n", "\n"+indent) + "\n")
+	}
+	if opts.Nav {
+		sb.WriteString("</nav>\n")
+	}
+	return sb.String()
+}
+
+// writeHTMLList writes the children of md as an HTML `<ul>` list indented by depth,
+// the content of each `<li>` element is rendered by entry.
+func writeHTMLList(sb *strings.Builder, md MDFileInfo, depth int, opts TocOptions, entry func(MDFileInfo) string) {
+	if len(md.Children) == 0 {
+		return
+	}
+	indent := strings.Repeat(opts.Indent, depth)
+	if opts.Nav {
+		sb.WriteString(indent + "<ul role=\"list\">\n")
+	} else {
+		sb.WriteString(indent + "<ul>\n")
+	}
+	for _, key := range SortedChildKeys(md, opts) {
+		child := md.Children[key]
+		sb.WriteString(indent + opts.Indent + "<li>" + entry(child))
+		if len(child.Children) > 0 {
+			sb.WriteString("\n")
+			writeHTMLList(sb, child, depth+2, opts, entry)
+			sb.WriteString(indent + opts.Indent)
+		}
+		sb.WriteString("</li>\n")
+	}
+	sb.WriteString(indent + "</ul>\n")
+}
+
+// htmlEntry renders the content of the `<li>` element of md, a link for files and linked
+// directories, and the title for other directories. The files get an edit link with opts.EditBaseURL.
+func htmlEntry(md MDFileInfo, opts TocOptions) string {
+	title := html.EscapeString(md.Title)
+	path := md.Path
+	if md.IsDir {
+		if md.LinkPath == "" {
+			return title
+		}
+		path = md.LinkPath
+	}
+	href := LinkTarget(MDFileInfo{Path: path}, opts)
+	current := ""
+	if opts.Nav && opts.NavCurrent != "" && isSamePath(path, opts.NavCurrent) {
+		current = " aria-current=\"page\""
+	}
+	entry := fmt.Sprintf("<a href=\"%s\"%s>%s</a>%s", html.EscapeString(href), current, title, html.EscapeString(EntryDetails(md, opts)))
+	if opts.EditBaseURL != "" && !md.IsDir {
+		entry += fmt.Sprintf(" <a href=\"%s\">%s</a>", html.EscapeString(EditURL(md, opts.EditBaseURL)), html.EscapeString(EditLinkText))
+	}
+	return entry
+}
+
+// isSamePath reports whether the escaped path of a TOC entry refers to the given relative path.
+func isSamePath(escapedPath, relPath string) bool {
+	path, err := url.PathUnescape(escapedPath)
+	if err != nil {
+		return false
+	}
+	return filepath.Clean(path) == filepath.Clean(relPath)
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCreateHTMLTree(t *testing.T) {
+	want := "<h1>Docs</h1>\n" +
+		"<ul>\n" +
+		"  <li>guides\n" +
+		"    <ul>\n" +
+		"      <li>advanced\n" +
+		"        <ul>\n" +
+		"          <li><a href=\"./guides/advanced/scaling.md\">Scaling</a></li>\n" +
+		"        </ul>\n" +
+		"      </li>\n" +
+		"      <li><a href=\"./guides/start.md\">Getting Started</a></li>\n" +
+		"    </ul>\n" +
+		"  </li>\n" +
+		"  <li><a href=\"./intro.md\">Intro</a></li>\n" +
+		"</ul>\n"
+	if got := CreateHTMLTree(sampleDocs(t), testTocOptions()); got != want {
+		t.Errorf("CreateHTMLTree() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestCreateHTMLTreeNav(t *testing.T) {
+	tests := []struct {
+		name    string
+		current string
+		want    []string
+		notWant []string
+	}{
+		{"nav", "", []string{"<nav aria-label=\"Table of contents\">\n  <h1>Docs</h1>\n", "<ul role=\"list\">", "</nav>\n"}, []string{"aria-current", "<ul>\n"}},
+		{"current page", "guides/start.md", []string{"<a href=\"./guides/start.md\" aria-current=\"page\">Getting Started</a>"}, []string{"<a href=\"./intro.md\" aria-current"}},
+		{"current with dot", "./intro.md", []string{"<a href=\"./intro.md\" aria-current=\"page\">Intro</a>"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := testTocOptions()
+			opts.Nav = true
+			opts.NavCurrent = tt.current
+			got := CreateHTMLTree(sampleDocs(t), opts)
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("output does not contain %q:\n%s", want, got)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(got, notWant) {
+					t.Errorf("output contains %q:\n%s", notWant, got)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateHTMLTreeEscapes(t *testing.T) {
+	md := MDFileInfo{Title: "A & B", IsDir: true, Children: map[string]MDFileInfo{
+		"x.md": {Name: "x.md", Title: "<script>", Level: 1, Path: "./x.md"},
+	}}
+	got := CreateHTMLTree(md, testTocOptions())
+	for _, want := range []string{"<h1>A &amp; B</h1>", ">&lt;script&gt;</a>"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output does not contain %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestCreateHTMLTreeSearch(t *testing.T) {
+	tests := []struct {
+		name   string
+		search bool
+		nav    bool
+		want   []string
+	}{
+		{"no search", false, false, nil},
+		{"search", true, false, []string{htmlSearchInput + "\n<ul>\n", "</ul>\n<script>\n", "</script>\n"}},
+		{"search in nav", true, true, []string{"  " + htmlSearchInput + "\n  <ul role=\"list\">\n", "  </ul>\n  <script>\n", "  </script>\n</nav>\n"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := testTocOptions()
+			opts.Search = tt.search
+			opts.Nav = tt.nav
+			got := CreateHTMLTree(sampleDocs(t), opts)
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("output does not contain %q:\n%s", want, got)
+				}
+			}
+			if !tt.search && (strings.Contains(got, "<input") || strings.Contains(got, "<script")) {
+				t.Errorf("output contains the search box:\n%s", got)
+			}
+			if strings.Contains(got, "src=") {
+				t.Errorf("output loads an external script:\n%s", got)
+			}
+		})
+	}
+}
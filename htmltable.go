@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+)
+
+// Columns of the html-table format, see the `-table-columns` flag.
+const (
+	TableColumnTitle    = "title"
+	TableColumnSection  = "section"
+	TableColumnModified = "modified"
+	TableColumnWords    = "words"
+)
+
+// DefaultTableColumns are the columns of the html-table format when `-table-columns` is not given.
+var DefaultTableColumns = []string{TableColumnTitle, TableColumnSection, TableColumnModified, TableColumnWords}
+
+// TableColumnHeaders maps the columns of the html-table format to the text of their header.
+var TableColumnHeaders = map[string]string{
+	TableColumnTitle:    "Title",
+	TableColumnSection:  "Section",
+	TableColumnModified: "Last Modified",
+	TableColumnWords:    "Word Count",
+}
+
+// ParseTableColumns parses a comma-separated list of columns of the html-table format.
+//
+// Parameters:
+// - value: the list, DefaultTableColumns if it is empty.
+//
+// Returns:
+// - []string: the columns, in order.
+// - error: an error if a column is unknown.
+func ParseTableColumns(value string) ([]string, error) {
+	columns := SplitList(value)
+	if len(columns) == 0 {
+		return DefaultTableColumns, nil
+	}
+	for _, column := range columns {
+		if _, ok := TableColumnHeaders[column]; !ok {
+			return nil, fmt.Errorf("unknown column %q", column)
+		}
+	}
+	return columns, nil
+}
+
+// CreateHTMLTable generates an HTML `<table>` under an `<h1>` title, with one row per file and
+// the opts.TableColumns columns, DefaultTableColumns if it is empty: the linked title, the section,
+// i.e. the titles of the directories leading to the file, the last modification date and the
+// number of words.
+//
+// Parameters:
+// - md: the MDFileInfo object representing the root directory.
+// - opts: the options used to render the TOC.
+//
+// Returns:
+// - string: the generated HTML.
+func CreateHTMLTable(md MDFileInfo, opts TocOptions) string {
+	columns := opts.TableColumns
+	if len(columns) == 0 {
+		columns = DefaultTableColumns
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("<h1>%s</h1>\n", html.EscapeString(md.Title)))
+	if opts.TocHeading != "" {
+		sb.WriteString(fmt.Sprintf("<h2>%s</h2>\n", html.EscapeString(opts.TocHeading)))
+	}
+	indent := opts.Indent
+	sb.WriteString("<table>\n" + indent + "<thead>\n" + indent + indent + "<tr>")
+	for _, column := range columns {
+		sb.WriteString("<th>" + html.EscapeString(TableColumnHeaders[column]) + "</th>")
+	}
+	sb.WriteString("</tr>\n" + indent + "</thead>\n" + indent + "<tbody>\n")
+	for _, entry := range FlattenFiles(md, opts) {
+		sb.WriteString(indent + indent + "<tr>")
+		for _, column := range columns {
+			sb.WriteString("<td>" + tableCell(entry, column, opts) + "</td>")
+		}
+		sb.WriteString("</tr>\n")
+	}
+	sb.WriteString(indent + "</tbody>\n</table>\n")
+	return sb.String()
+}
+
+// tableCell renders the escaped content of the cell of an entry in the given column.
+func tableCell(entry FlatEntry, column string, opts TocOptions) string {
+	switch column {
+	case TableColumnTitle:
+		return fmt.Sprintf("<a href=\"%s\">%s</a>", html.EscapeString(LinkTarget(entry.File, opts)), html.EscapeString(entry.File.Title))
+	case TableColumnSection:
+		return html.EscapeString(entry.Section())
+	case TableColumnModified:
+		if entry.File.ModTime.IsZero() {
+			return ""
+		}
+		return html.EscapeString(entry.File.ModTime.Format(opts.DateFormat))
+	case TableColumnWords:
+		return strconv.Itoa(entry.File.WordCount)
+	}
+	return ""
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseTableColumns(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    []string
+		wantErr bool
+	}{
+		{"", DefaultTableColumns, false},
+		{"title, words", []string{TableColumnTitle, TableColumnWords}, false},
+		{"modified,title", []string{TableColumnModified, TableColumnTitle}, false},
+		{"title,author", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseTableColumns(tt.value)
+		if (err != nil) != tt.wantErr || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseTableColumns(%q) = %q, %v, want %q, wantErr %v", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestCreateHTMLTable(t *testing.T) {
+	modTime := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	md := MDFileInfo{Title: "Docs & Co", IsDir: true, Children: map[string]MDFileInfo{
+		"guides": {Name: "guides", Title: "Guides", IsDir: true, Level: 1, Children: map[string]MDFileInfo{
+			"start.md": {Name: "start.md", Title: "<Start>", Level: 2, Path: "./guides/start.md", ModTime: modTime, WordCount: 120},
+		}},
+		"remote.md": {Name: "remote.md", Title: "Remote", Level: 1, Path: "./remote.md", WordCount: 3},
+	}}
+	tests := []struct {
+		name    string
+		columns []string
+		want    string
+	}{
+		{"default", nil, "<h1>Docs &amp; Co</h1>\n" +
+			"<table>\n" +
+			"  <thead>\n" +
+			"    <tr><th>Title</th><th>Section</th><th>Last Modified</th><th>Word Count</th></tr>\n" +
+			"  </thead>\n" +
+			"  <tbody>\n" +
+			"    <tr><td><a href=\"./guides/start.md\">&lt;Start&gt;</a></td><td>Guides</td><td>2024-01-02</td><td>120</td></tr>\n" +
+			"    <tr><td><a href=\"./remote.md\">Remote</a></td><td></td><td></td><td>3</td></tr>\n" +
+			"  </tbody>\n" +
+			"</table>\n"},
+		{"columns", []string{TableColumnWords, TableColumnTitle}, "<h1>Docs &amp; Co</h1>\n" +
+			"<table>\n" +
+			"  <thead>\n" +
+			"    <tr><th>Word Count</th><th>Title</th></tr>\n" +
+			"  </thead>\n" +
+			"  <tbody>\n" +
+			"    <tr><td>120</td><td><a href=\"./guides/start.md\">&lt;Start&gt;</a></td></tr>\n" +
+			"    <tr><td>3</td><td><a href=\"./remote.md\">Remote</a></td></tr>\n" +
+			"  </tbody>\n" +
+			"</table>\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := testTocOptions()
+			opts.TableColumns = tt.columns
+			opts.DateFormat = "2006-01-02"
+			if got := CreateHTMLTable(md, opts); got != tt.want {
+				t.Errorf("CreateHTMLTable() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
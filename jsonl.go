@@ -0,0 +1,38 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// jsonlEntry is a line of the JSON Lines output.
+type jsonlEntry struct {
+	Title   string `json:"title"`
+	Path    string `json:"path"`
+	Section string `json:"section"`
+	Depth   int    `json:"depth"`
+}
+
+// CreateJSONLines generates one JSON object per file, one per line, with the title, the path,
+// the section and the depth of the file. This suits line-oriented tools such as search indexers.
+//
+// Parameters:
+// - md: the MDFileInfo object representing the root directory.
+// - opts: the options used to render the TOC.
+//
+// Returns:
+// - string: the generated JSON Lines.
+func CreateJSONLines(md MDFileInfo, opts TocOptions) string {
+	var sb strings.Builder
+	for _, entry := range FlattenFiles(md, opts) {
+		line, _ := json.Marshal(jsonlEntry{
+			Title:   entry.File.Title,
+			Path:    RelPath(entry.File),
+			Section: entry.Section(),
+			Depth:   entry.File.Level,
+		})
+		sb.Write(line)
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestCreateJSONLines(t *testing.T) {
+	got := CreateJSONLines(sampleDocs(t), testTocOptions())
+	want := `{"title":"Scaling","path":"guides/advanced/scaling.md","section":"guides / advanced","depth":3}
+{"title":"Getting Started","path":"guides/start.md","section":"guides","depth":2}
+{"title":"Intro","path":"intro.md","section":"","depth":1}
+`
+	if got != want {
+		t.Errorf("CreateJSONLines() =\n%s\nwant\n%s", got, want)
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(got, "\n"), "\n") {
+		var entry jsonlEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Errorf("invalid line %q: %v", line, err)
+		}
+	}
+}
+
+func TestCreateJSONLinesEscapes(t *testing.T) {
+	md := MDFileInfo{IsDir: true, Children: map[string]MDFileInfo{
+		"q.md": {Name: "q.md", Title: "Say \"hi\"\n", Level: 1, Path: "./q.md"},
+	}}
+	want := `{"title":"Say \"hi\"\n","path":"q.md","section":"","depth":1}` + "\n"
+	if got := CreateJSONLines(md, testTocOptions()); got != want {
+		t.Errorf("CreateJSONLines() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SectionJumplist renders the list of links to the `##` sections of the Markdown TOC, the entries
+// of the first level, placed under the title so that the sections of a long TOC can be reached
+// at once. The anchors are the ones the headings get with opts.SlugStyle, a repeated heading being
+// suffixed with `-1`, `-2`, and so on, as GitHub does. It is an empty string when the first level
+// is not rendered as headings.
+//
+// Parameters:
+// - md: the MDFileInfo object representing the root directory.
+// - opts: the options used to render the TOC.
+//
+// Returns:
+// - string: the rendered list.
+func SectionJumplist(md MDFileInfo, opts TocOptions) string {
+	if opts.HeadingDepth < 1 {
+		return ""
+	}
+	counts := make(map[string]int)
+	anchor := func(text string) string {
+		slug := Slugify(text, opts.SlugStyle)
+		n := counts[slug]
+		counts[slug]++
+		if n > 0 {
+			return fmt.Sprintf("%s-%d", slug, n)
+		}
+		return slug
+	}
+	anchor(md.Title)
+	if opts.TocHeading != "" {
+		anchor(opts.TocHeading)
+	}
+	var links []string
+	// The headings are walked in rendering order, the deeper ones taking their anchors too
+	var walk func(node MDFileInfo)
+	walk = func(node MDFileInfo) {
+		keys := SortedChildKeys(node, opts)
+		if node.Level > 0 && opts.MaxEntries > 0 && len(keys) > opts.MaxEntries {
+			keys = keys[:opts.MaxEntries]
+		}
+		for _, key := range keys {
+			child := node.Children[key]
+			if child.Level > opts.HeadingDepth || (opts.TaskList && !child.IsDir) {
+				// List items, not headings
+				continue
+			}
+			target := anchor(headingText(child, opts))
+			if child.Level == 1 {
+				links = append(links, fmt.Sprintf("%s[%s](#%s)\n", ListMarker, EscapeLinkText(child.Title), target))
+			}
+			walk(child)
+		}
+	}
+	walk(md)
+	if len(links) == 0 {
+		return ""
+	}
+	if opts.Compact {
+		return strings.Join(links, "")
+	}
+	return "\n" + strings.Join(links, "")
+}
+
+// headingText returns the plain text of the heading of a node of the Markdown TOC, from which
+// its anchor is derived: its title followed by the details and the edit link of a file.
+func headingText(md MDFileInfo, opts TocOptions) string {
+	text := md.Title + EntryDetails(md, opts)
+	if opts.EditBaseURL != "" && !md.IsDir {
+		text += " " + EditLinkText
+	}
+	return text
+}
@@ -0,0 +1,45 @@
+package main
+
+import "testing"
+
+func TestSectionJumplist(t *testing.T) {
+	md := listTree(t, writeTree(t, map[string]string{
+		"docs.md":      "# Docs\n",
+		"guides/a.md":  "# Guides\n",
+		"guides2/b.md": "# B\n",
+	}), testListOptions())
+	md.Title = "Docs"
+	dir := md.Children["guides2"]
+	dir.Title = "guides"
+	md.Children["guides2"] = dir
+	tests := []struct {
+		name  string
+		depth int
+		want  string
+	}{
+		{"sections", 1, "\n- [Docs](#docs-1)\n- [guides](#guides)\n- [guides](#guides-1)\n"},
+		// The heading of guides/a.md takes the guides-1 anchor before the second section
+		{"deeper headings", 2, "\n- [Docs](#docs-1)\n- [guides](#guides)\n- [guides](#guides-2)\n"},
+		{"no headings", 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := testTocOptions()
+			opts.HeadingDepth = tt.depth
+			if got := SectionJumplist(md, opts); got != tt.want {
+				t.Errorf("SectionJumplist() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	opts := testTocOptions()
+	opts.SectionJumplist = true
+	want := "# Docs\n\n" +
+		"- [Docs](#docs-1)\n- [guides](#guides)\n- [guides](#guides-1)\n\n" +
+		"## [Docs](./docs.md)\n\n\n" +
+		"## guides\n\n- [Guides](./guides/a.md)\n\n" +
+		"## guides\n\n- [B](./guides2/b.md)\n"
+	if got := CreateTocTree(md, opts); got != want {
+		t.Errorf("CreateTocTree() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFileLang(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"page.en.md", "en"},
+		{"page.fr.md", "fr"},
+		{"page.pt-BR.md", "pt-BR"},
+		{"page.zh-Hant.md", "zh-Hant"},
+		{"page.md", ""},
+		{"setup.old.md", ""},
+		{"v2.api.md", ""},
+		{"notes.xx.md", ""},
+		{"page.EN.md", ""},
+		{"page.en-", ""},
+	}
+	for _, tt := range tests {
+		if got := FileLang(tt.name); got != tt.want {
+			t.Errorf("FileLang(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestListMDFilesLang(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"page.en.md", "page.fr.md", "about.md", "setup.old.md"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("# "+name+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files, err := ListMDFiles(dir, ListOptions{Lang: "en"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, key := range SortedChildKeys(files, TocOptions{SortAsc: true}) {
+		got = append(got, key)
+	}
+	want := []string{"about.md", "page.en.md", "setup.old.md"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ListMDFiles() lists %q, want %q", got, want)
+	}
+}
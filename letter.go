@@ -0,0 +1,54 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// CreateLetterIndex generates an alphabetical index of the files, ignoring the directories: the
+// files are grouped under a `## A`, `## B`, ... heading by the first letter of their title, the
+// titles which do not start with a letter are grouped under `## #`, before the letters.
+//
+// Parameters:
+// - md: the MDFileInfo object representing the root directory.
+// - opts: the options used to render the TOC.
+//
+// Returns:
+// - string: the generated Markdown.
+func CreateLetterIndex(md MDFileInfo, opts TocOptions) string {
+	groups := make(map[string][]MDFileInfo)
+	for _, entry := range FlattenFiles(md, opts) {
+		letter := TitleInitial(entry.File.Title)
+		groups[letter] = append(groups[letter], entry.File)
+	}
+	letters := make([]string, 0, len(groups))
+	for letter := range groups {
+		letters = append(letters, letter)
+	}
+	sort.Strings(letters)
+
+	var sb strings.Builder
+	sb.WriteString(RootHeading(md, opts))
+	for _, letter := range letters {
+		files := groups[letter]
+		sort.SliceStable(files, func(i, j int) bool {
+			return strings.ToLower(files[i].Title) < strings.ToLower(files[j].Title)
+		})
+		sb.WriteString("\n## " + letter + "\n\n")
+		for _, file := range files {
+			sb.WriteString(ListMarker + EntryText(file, opts) + "\n")
+		}
+	}
+	return sb.String()
+}
+
+// TitleInitial returns the upper-cased first letter of a title, or `#` if it does not start with a letter.
+func TitleInitial(title string) string {
+	r, _ := utf8.DecodeRuneInString(strings.TrimSpace(title))
+	if !unicode.IsLetter(r) {
+		return "#"
+	}
+	return string(unicode.ToUpper(r))
+}
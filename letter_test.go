@@ -0,0 +1,47 @@
+package main
+
+import "testing"
+
+func TestTitleInitial(t *testing.T) {
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{"Intro", "I"},
+		{"apis", "A"},
+		{"  spaced", "S"},
+		{"élan", "É"},
+		{"2024 notes", "#"},
+		{"_draft", "#"},
+		{"", "#"},
+	}
+	for _, tt := range tests {
+		if got := TitleInitial(tt.title); got != tt.want {
+			t.Errorf("TitleInitial(%q) = %q, want %q", tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestCreateLetterIndex(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"intro.md":          "# Intro\n",
+		"guides/start.md":   "# getting Started\n",
+		"guides/install.md": "# Installation\n",
+		"notes/2024.md":     "# 2024 Review\n",
+		"notes/glossary.md": "# Glossary\n",
+	})
+	md := listTree(t, dir, testListOptions())
+	md.Title = "Docs"
+	want := "# Docs\n" +
+		"\n## #\n\n" +
+		"- [2024 Review](./notes/2024.md)\n" +
+		"\n## G\n\n" +
+		"- [getting Started](./guides/start.md)\n" +
+		"- [Glossary](./notes/glossary.md)\n" +
+		"\n## I\n\n" +
+		"- [Installation](./guides/install.md)\n" +
+		"- [Intro](./intro.md)\n"
+	if got := CreateLetterIndex(md, testTocOptions()); got != want {
+		t.Errorf("CreateLetterIndex() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !windows
+
+package main
+
+// FixLongPath returns the path unchanged, only Windows limits the length of the paths.
+func FixLongPath(path string) string {
+	return path
+}
@@ -0,0 +1,13 @@
+//go:build !windows
+
+package main
+
+import "testing"
+
+func TestFixLongPath(t *testing.T) {
+	for _, path := range []string{"docs", "/srv/docs", `\\server\share`, ""} {
+		if got := FixLongPath(path); got != path {
+			t.Errorf("FixLongPath(%q) = %q, want it unchanged", path, got)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+//go:build windows
+
+package main
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// longPathPrefix is the prefix which lifts the MAX_PATH limit of the Windows file APIs.
+const longPathPrefix = `\\?\`
+
+// FixLongPath returns the extended-length form of the given path so that deep directory trees
+// beyond the 260 characters MAX_PATH limit can be walked. UNC paths such as `\\server\share`
+// become `\\?\UNC\server\share`. Paths which are already in the extended-length form or
+// cannot be made absolute are returned unchanged.
+//
+// Parameters:
+// - path: the path of the directory to walk.
+//
+// Returns:
+// - string: the extended-length path.
+func FixLongPath(path string) string {
+	if strings.HasPrefix(path, longPathPrefix) {
+		return path
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	if strings.HasPrefix(abs, `\\`) {
+		return longPathPrefix + `UNC\` + strings.TrimPrefix(abs, `\\`)
+	}
+	return longPathPrefix + abs
+}
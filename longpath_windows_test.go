@@ -0,0 +1,49 @@
+//go:build windows
+
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestFixLongPath(t *testing.T) {
+	abs, err := filepath.Abs("docs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		path string
+		want string
+	}{
+		{`C:\docs\guides`, `\\?\C:\docs\guides`},
+		{`\\server\share\docs`, `\\?\UNC\server\share\docs`},
+		{`\\?\C:\docs`, `\\?\C:\docs`},
+		{`\\?\UNC\server\share`, `\\?\UNC\server\share`},
+		{"docs", `\\?\` + abs},
+	}
+	for _, tt := range tests {
+		if got := FixLongPath(tt.path); got != tt.want {
+			t.Errorf("FixLongPath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestRelOutFileLongPath(t *testing.T) {
+	// ListMDFiles walks the extended-length form of the directory, the output file is given as is
+	dir := t.TempDir()
+	tests := []struct {
+		outFile string
+		want    string
+	}{
+		{filepath.Join(dir, "TOC.md"), "TOC.md"},
+		{filepath.Join(dir, "guides", "TOC.md"), filepath.Join("guides", "TOC.md")},
+		{`\\?\` + filepath.Join(dir, "TOC.md"), "TOC.md"},
+		{filepath.Join(filepath.Dir(dir), "TOC.md"), ""},
+	}
+	for _, tt := range tests {
+		if got := relOutFile(FixLongPath(dir), tt.outFile); got != tt.want {
+			t.Errorf("relOutFile(%q) = %q, want %q", tt.outFile, got, tt.want)
+		}
+	}
+}
@@ -2,68 +2,847 @@ package main
 
 import (
 	"bufio"
+	"crypto/sha256"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
-	"reflect"
 	"regexp"
-	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"text/template"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// IgnoreFileName is the name of the file, located in the root directory, which lists
+// glob patterns of files and directories to leave out of the TOC.
+const IgnoreFileName = ".mdtocignore"
+
+// DirTitleFileNames are the names of the files which override the title of the directory they are in.
+var DirTitleFileNames = []string{".title", "_title"}
+
+// HugoIndexFileName is the name of the file holding the frontmatter of a Hugo section.
+const HugoIndexFileName = "_index.md"
+
+// DirIndexFileNames are the names of the files which serve as the index of the directory they are in.
+var DirIndexFileNames = []string{"README.md", "index.md"}
+
+// Values of the `-mark-drafts` flag.
+const (
+	DraftsExclude  = "exclude"
+	DraftsAnnotate = "annotate"
+)
+
+// Link styles supported by the `-link-style` flag.
+const (
+	LinkStyleMarkdown = "markdown"
+	LinkStyleWiki     = "wiki"
+)
+
+// Output formats supported by the `-format` flag.
+const (
+	FormatMarkdown    = "markdown"
+	FormatHTML        = "html"
+	FormatBlockquote  = "blockquote"
+	FormatJSONLines   = "jsonl"
+	FormatOPML        = "opml"
+	FormatBreadcrumbs = "breadcrumbs"
+	FormatText        = "text"
+	FormatChecksums   = "checksums"
+	FormatAsciiDoc    = "adoc"
+	FormatConfluence  = "confluence"
+	FormatMkDocsNav   = "mkdocs-nav"
+	FormatFlat        = "flat"
+	FormatPDFOutline  = "pdf-outline"
+	FormatManifest    = "manifest"
+	FormatDOT         = "dot"
+	FormatYAML        = "yaml"
+	FormatHTMLTable   = "html-table"
+	FormatInline      = "inline"
+	FormatDocusaurus  = "docusaurus"
+	// FormatGitHubComment is the Markdown TOC with absolute links to GitHub, to paste in an issue or a comment.
+	FormatGitHubComment = "github-comment"
 )
 
+// Formats lists the output formats supported by the `-format` flag.
+var Formats = []string{FormatMarkdown, FormatHTML, FormatJSONLines, FormatOPML, FormatBreadcrumbs, FormatText, FormatChecksums, FormatAsciiDoc, FormatConfluence, FormatMkDocsNav, FormatFlat, FormatPDFOutline, FormatManifest, FormatDOT, FormatYAML, FormatGitHubComment, FormatHTMLTable, FormatInline, FormatDocusaurus, FormatBlockquote}
+
+// IsKnownFormat reports whether the given name is one of the supported output formats.
+func IsKnownFormat(format string) bool {
+	for _, f := range Formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
 type MDFileInfo struct {
-	IsDir    bool
-	Children map[string]MDFileInfo
-	Title    string
-	Level    int
-	Path     string
+	Name         string
+	IsDir        bool
+	Children     map[string]MDFileInfo
+	Title        string
+	Level        int
+	Path         string
+	IndexPath    string
+	LinkPath     string
+	ModTime      time.Time
+	Checksum     string
+	Frontmatter  map[string]string
+	HeadingCount int
+	WordCount    int
+}
+
+// ListOptions holds the settings used to discover the Markdown files.
+type ListOptions struct {
+	TitleStrategy   []TitleSource  // the title sources tried in order, see ParseTitleStrategy
+	ReadmeAsSection bool           // whether README.md files title and link their directory instead of being skipped
+	Checksums       bool           // whether the SHA-256 of the files is computed
+	Include         []string       // if not empty, only the files matching one of these glob patterns are listed
+	Exclude         []string       // the glob patterns of the files and directories which are not listed
+	MarkDrafts      string         // how draft files are handled: DraftsExclude, DraftsAnnotate, or empty to list them as usual
+	Lang            string         // if not empty, the files with another language suffix are not listed
+	Parallel        int            // the number of goroutines walking the subdirectories, the walk is serial below 2
+	Progress        *Progress      // reports the processed files, nil to report nothing
+	ExcludeTitle    *regexp.Regexp // if not nil, the files whose title matches it are not listed
+	OutFile         string         // if not empty, the output file, which is not listed when it is in the directory
+	SidecarExt      string         // if not empty, the extension of the sidecar files whose title overrides the one of their file
+	MaxFiles        int            // if positive, listing more Markdown files is an error
+	Hugo            bool           // whether the _index.md files title and link their directory instead of being listed
+	ModifiedSince   time.Time      // if not zero, the files modified before it are not listed
+	SkipDirs        []string       // the names of the directories which are not walked wherever they are, see DefaultSkipDirs
+	SingleH1        bool           // whether a file with several H1 headers is an error
+}
+
+// DefaultSkipDirs are the names of the directories left out by default, wherever they are in the tree:
+// they hold the files of tools and dependencies rather than documentation.
+var DefaultSkipDirs = []string{".git", "node_modules", "vendor"}
+
+// TocOptions holds the settings used to render the TOC.
+type TocOptions struct {
+	Indent            string             // the string used for indentation in the TOC
+	SortAsc           bool               // whether the TOC should be sorted in ascending order
+	Sort              string             // the sort key, one of SortComparators
+	LinkStyle         string             // LinkStyleMarkdown or LinkStyleWiki
+	Format            string             // one of the Format constants
+	Nav               bool               // whether the HTML output is wrapped in a <nav> element
+	NavCurrent        string             // the path of the current page in the HTML output
+	TocHeading        string             // the heading added under the title, if not empty
+	SlugStyle         string             // SlugStyleGitHub or SlugStylePandoc
+	TaskList          bool               // whether the files are rendered as task-list items
+	Search            bool               // whether the HTML output has a filter box
+	CollapseThreshold int                // the sections with more entries are collapsed in a <details> element, 0 disables it
+	AnchorMode        bool               // whether the links point to per-file anchors of a combined document
+	ShowDates         bool               // whether the modification date of the files is appended to their entry
+	DateFormat        string             // the time layout of the dates, e.g. 2006-01-02
+	EntryFormat       *template.Template // the template of the flat and breadcrumbs entries, nil for the default one
+	ShowAge           bool               // whether the age of the files is appended to their entry, e.g. 3 days ago
+	Now               time.Time          // the time the ages are relative to, the current time if zero
+	HeadingDepth      int                // the number of levels rendered as headings, see FormatHeadingDepths
+	MaxEntries        int                // the number of entries shown per section directory, the others are summed up, 0 shows all
+	NamespaceAnchors  bool               // whether the anchors of the directories are derived from their path rather than their title
+	ShowHeadingCount  bool               // whether the number of headings of the files is appended to their entry
+	Wrap              int                // the number of columns the titles of the text format are wrapped to, 0 not to wrap
+	GitHub            GitHubRepo         // the repository the links of the github-comment format point to
+	TableColumns      []string           // the columns of the html-table format, DefaultTableColumns if empty
+	Compact           bool               // whether the blank lines around the headings of the Markdown TOC are left out
+	EditBaseURL       string             // if not empty, the base URL of the edit links appended to the file entries, see EditURL
+	Order             map[string]int     // the positions of the paths of the order file, see LoadOrderFile
+	SectionJumplist   bool               // whether the links to the sections of the Markdown TOC are listed under its title
 }
 
 func main() {
 	var (
-		wd      string
-		outFile string
-		title   string
-		sortAsc bool
+		wd         string
+		outFile    string
+		title      string
+		sortAsc    bool
+		prepend    string
+		appendF    string
+		linkStyle  string
+		format     string
+		titleStgy  string
+		secNums    bool
+		nav        bool
+		navCurr    string
+		update     bool
+		printTree  bool
+		maxDepth   int
+		tocHead    string
+		dirsOnly   bool
+		slugStyle  string
+		readmeSec  bool
+		include    string
+		exclude    string
+		drafts     string
+		lang       string
+		taskList   bool
+		parallel   int
+		anyHead    bool
+		sortBy     string
+		search     bool
+		remoteURL  string
+		remote     RemoteOptions
+		headers    HeaderFlag
+		collapse   int
+		anchors    bool
+		progress   bool
+		showDates  bool
+		dateFmt    string
+		fenced     bool
+		postCmd    string
+		expected   string
+		tee        bool
+		fixTitles  bool
+		dryRun     bool
+		byLetter   bool
+		validate   bool
+		entryFmt   string
+		dirReadmes bool
+		force      bool
+		humanize   bool
+		acronyms   string
+		exclTitle  string
+		selfExcl   bool
+		showAge    bool
+		headDepth  int
+		diffMan    string
+		newline    string
+		titleRe    string
+		maxEntries int
+		sidecar    string
+		titleCase  string
+		clipboard  bool
+		nsAnchors  bool
+		maxFiles   int
+		headCount  bool
+		hugo       bool
+		since      string
+		wrap       int
+		ghRepo     string
+		ghRef      string
+		ghPath     string
+		skipDirs   string
+		tableCols  string
+		compact    bool
+		editBase   string
+		lintHeads  bool
+		concat     bool
+		config     string
+		initConfig bool
+		multiH1    string
+		gzipOut    bool
+		iconKeys   string
+		reqTitles  bool
+		preview    bool
+		orderFile  string
+		jumplist   bool
+		quiet      bool
 	)
 	flag.StringVar(&wd, "dir", ".", "Directory to read the file")
 	flag.StringVar(&outFile, "out", "", "Output file")
 	flag.StringVar(&title, "t", "", "Title of output file, default is the `dir`")
 	flag.BoolVar(&sortAsc, "asc", true, "Order the TOC in ascending order, if false, it will be in descending order")
+	flag.StringVar(&prepend, "prepend", "", "File whose content is inserted before the TOC")
+	flag.StringVar(&appendF, "append", "", "File whose content is inserted after the TOC")
+	flag.StringVar(&linkStyle, "link-style", LinkStyleMarkdown, "Style of the links: markdown or wiki")
+	flag.StringVar(&format, "format", FormatMarkdown, "Output format: one of "+strings.Join(Formats, ", ")+" (blockquote is experimental)")
+	flag.StringVar(&titleStgy, "title-strategy", strings.Join(DefaultTitleStrategy, ","), "Comma-separated title sources tried in order: frontmatter, h1, heading, setext, html, first-line")
+	flag.BoolVar(&secNums, "section-numbers", false, "Prefix each entry with its hierarchical section number, e.g. 1.2")
+	flag.BoolVar(&nav, "nav", false, "Wrap the HTML output in an accessible <nav> element")
+	flag.StringVar(&navCurr, "nav-current", "", "Path of the current page, its HTML link is marked with aria-current")
+	flag.BoolVar(&update, "update", false, "Only regenerate the sections of the -out file whose generated text changed since it was written, compared by hash rather than file modification time")
+	flag.BoolVar(&printTree, "print-tree", false, "Print the discovered files to stderr before rendering, for debugging")
+	flag.IntVar(&maxDepth, "max-depth", 0, "Maximum depth of the entries in the TOC, 0 means unlimited")
+	flag.StringVar(&tocHead, "toc-heading", "", "Heading added under the title, before the sections, e.g. \"Contents\"")
+	flag.BoolVar(&dirsOnly, "dirs-only", false, "Only list the directories, without the files")
+	flag.StringVar(&slugStyle, "slug-style", SlugStyleGitHub, "Style of the generated anchors: github or pandoc")
+	flag.BoolVar(&readmeSec, "readme-as-section", false, "Title each directory after its README.md and link the section to it")
+	flag.StringVar(&include, "include", "", "Comma-separated glob patterns, only the matching files are listed")
+	flag.StringVar(&exclude, "exclude", "", "Comma-separated glob patterns of the files and directories to leave out")
+	flag.StringVar(&drafts, "mark-drafts", "", "How to handle files with draft: true or published: false in their frontmatter: exclude or annotate")
+	flag.StringVar(&lang, "lang", "", "Only list the files with this language suffix, e.g. en for page.en.md, and the files without one")
+	flag.BoolVar(&taskList, "task-list", false, "Render the files as task-list items, checked when their frontmatter has reviewed: true")
+	flag.IntVar(&parallel, "parallel", 1, "Number of directories walked concurrently")
+	flag.BoolVar(&anyHead, "any-heading", false, "Title the files with their first header of any level instead of the first H1")
+	flag.StringVar(&sortBy, "sort", SortName, "Sort key of the entries: name, weight (the weight field of the frontmatter, or of _index.md for directories), mtime or mtime-oldest (directories by their newest or oldest file), depth (shallow entries first), none (listing order, i.e. by name whatever -asc), date (the date field of the frontmatter, newest first)")
+	flag.BoolVar(&search, "search", false, "Add a filter box to the HTML output")
+	flag.StringVar(&remoteURL, "url", "", "URL of a remote listing to read instead of -dir: a GitHub API tree or a JSON array of paths")
+	flag.StringVar(&remote.RawBase, "raw-base", "", "Base URL the files of the -url listing are fetched from, default is the directory of the listing")
+	flag.DurationVar(&remote.Timeout, "http-timeout", 30*time.Second, "Timeout of the HTTP requests")
+	flag.Var(&headers, "http-header", "Header sent with the HTTP requests, e.g. \"Authorization: Bearer TOKEN\", may be repeated")
+	flag.IntVar(&collapse, "collapse-threshold", 0, "Collapse the sections with more entries than this in a <details> element, 0 disables it")
+	flag.BoolVar(&anchors, "anchor-mode", false, "Link to per-file anchors, e.g. #file-guides-start, for a document combining all the files")
+	flag.BoolVar(&progress, "progress", false, "Print the number of processed files to stderr while scanning")
+	flag.BoolVar(&quiet, "quiet", false, "Do not print the progress to stderr, even with -progress")
+	flag.BoolVar(&showDates, "show-dates", false, "Append the last modification date of the files to their entry")
+	flag.StringVar(&dateFmt, "date-format", "2006-01-02", "Go time layout of the dates shown with -show-dates")
+	flag.BoolVar(&fenced, "fenced", false, "Wrap the Markdown TOC in a markdown code fence to show it literally")
+	flag.StringVar(&postCmd, "postprocess", "", "Shell command the output is piped through before it is written")
+	flag.StringVar(&expected, "expected", "", "File listing the expected topics, one path or title per line, to report which ones exist instead of the TOC")
+	flag.BoolVar(&tee, "tee", false, "Also print the output written to -out on stdout")
+	flag.BoolVar(&fixTitles, "fix-titles", false, "Insert an H1 derived from the file name in the files which have none, below their frontmatter")
+	flag.BoolVar(&dryRun, "dry-run", false, "With -fix-titles, only report the files lacking an H1 without modifying them")
+	flag.BoolVar(&byLetter, "index-by-letter", false, "Group the files by the first letter of their title in an A-Z index, ignoring the directories")
+	flag.BoolVar(&validate, "validate", false, "Check that all the files are readable, titled and free of case conflicts before generating, reporting all the problems")
+	flag.StringVar(&entryFmt, "entry-format", "", "Go template of the flat and breadcrumbs entries, with the fields .Title, .Path, .Link, .Section, .Breadcrumbs and .Depth, e.g. '{{.Title}} — {{.Path}}'")
+	flag.BoolVar(&dirReadmes, "dir-readmes", false, "Write a README.md with the TOC of its own contents in every directory, instead of the output")
+	flag.BoolVar(&force, "force", false, "With -dir-readmes, overwrite the README.md files which were not generated, with -init, the existing configuration file")
+	flag.BoolVar(&humanize, "humanize-dirs", false, "Title the directories named like api_reference or api-reference as Api Reference")
+	flag.StringVar(&acronyms, "acronyms", "", "Comma-separated words kept in upper case in the titles derived from names, e.g. API,URL,HTTP")
+	flag.StringVar(&exclTitle, "exclude-title", "", "Regular expression of the titles of the files which are not listed, e.g. ^WIP:")
+	flag.BoolVar(&selfExcl, "self-exclude", true, "Leave the -out file out of the TOC when it is in the scanned directory")
+	flag.BoolVar(&showAge, "show-age", false, "Append the age of the files to their entry, e.g. 3 days ago")
+	flag.IntVar(&headDepth, "heading-depth", -1, "Number of levels rendered as headings rather than list items, -1 for the default of the format (1 for markdown, 6 for pdf-outline)")
+	flag.StringVar(&diffMan, "diff-manifest", "", "Manifest written with -format manifest to report the added, removed and retitled files since, instead of the TOC")
+	flag.StringVar(&newline, "trailing-newline", NewlineSingle, "Trailing newline of the output: single or none")
+	flag.StringVar(&titleRe, "title-regex", "", "Regular expression whose first capture group is the title, tried before the title strategy, e.g. '^<!-- title: (.*) -->$'")
+	flag.IntVar(&maxEntries, "max-entries-per-section", 0, "Show the first entries of every section directory and sum up the others in a '... and N more' line, 0 shows all")
+	flag.StringVar(&sidecar, "sidecar-ext", "", "Extension of the sidecar metadata files whose title overrides the one of their file, e.g. .meta for page.md.meta or .yaml for page.yaml")
+	flag.StringVar(&titleCase, "title-case", TitleCaseNone, "Case of the displayed titles: none, lower, upper or title")
+	flag.BoolVar(&clipboard, "clipboard", false, "Copy the output to the clipboard instead of printing it, -out is still written")
+	flag.BoolVar(&nsAnchors, "namespace-anchors", false, "Derive the anchors of the directories from their path, e.g. #file-guides-advanced, so that they are unique")
+	flag.IntVar(&maxFiles, "max-files", 0, "Abort if more Markdown files are found, e.g. when run at the file system root by mistake, 0 for no limit")
+	flag.BoolVar(&headCount, "show-heading-count", false, "Append the number of headings of the files to their entry, a single one may be a stub")
+	flag.BoolVar(&hugo, "hugo", false, "Treat the directories with an _index.md as Hugo sections, titled by and linking to it, sorted by weight unless -sort is given")
+	flag.StringVar(&since, "since", "", "Only list the files modified within this duration, e.g. 7d, 2w or 36h")
+	flag.IntVar(&wrap, "wrap", 0, "Wrap the titles of the text format to this many columns, 0 not to wrap")
+	flag.StringVar(&ghRepo, "github-repo", "", "Repository the links of the github-comment format point to, as owner/repo, default is $GITHUB_REPOSITORY")
+	flag.StringVar(&ghPath, "github-path", "", "Directory of -dir in the repository the links of the github-comment format point to, e.g. docs, default is derived from the git working tree")
+	flag.StringVar(&ghRef, "github-ref", "", "Branch, tag or commit the links of the github-comment format point to, default is $GITHUB_SHA or HEAD")
+	flag.StringVar(&skipDirs, "skip-dir", strings.Join(DefaultSkipDirs, ","), "Comma-separated names of the directories left out wherever they are, empty to walk them all")
+	flag.StringVar(&tableCols, "table-columns", strings.Join(DefaultTableColumns, ","), "Comma-separated columns of the html-table format: title, section, modified, words")
+	flag.BoolVar(&compact, "compact", false, "Leave out the blank lines around the headings of the Markdown TOC for a denser output")
+	flag.StringVar(&editBase, "edit-base-url", "", "Base URL the paths of the files are joined to for an edit link after their entry, e.g. https://github.com/OWNER/REPO/edit/main/docs/")
+	flag.BoolVar(&lintHeads, "lint-headings", false, "Warn on stderr about the files whose headings skip a level, e.g. H1 then H3, or which have several H1 headers")
+	flag.BoolVar(&concat, "concat", false, "Append all the files after the TOC in one document, their headings demoted by their depth, the TOC linking to their anchors")
+	flag.StringVar(&multiH1, "multiple-h1", MultipleH1First, "Title of the files with several H1 headers: first, last, or error to fail")
+	flag.BoolVar(&gzipOut, "gzip", false, "Compress the -out file with gzip, adding .gz to its name")
+	flag.StringVar(&iconKeys, "frontmatter-icon-key", "", "Comma-separated frontmatter fields whose value, e.g. an emoji, prefixes the title of the entries, e.g. icon,emoji")
+	flag.BoolVar(&reqTitles, "require-titles", false, "Fail, listing them, if the title strategy finds no title in some files")
+	flag.BoolVar(&preview, "preview", false, "Print the output to stderr with line numbers instead of writing it, marking with * the lines which differ from the -out file")
+	flag.StringVar(&orderFile, "order-file", "", "File listing relative paths, one per line, rendered first in this order across the tree, the others follow in the -sort order")
+	flag.BoolVar(&jumplist, "section-jumplist", false, "List the links to the sections of the Markdown TOC under its title")
+	flag.StringVar(&config, "config", ConfigFileName, "YAML configuration file setting the flags not given on the command line, keyed by their name")
+	flag.BoolVar(&initConfig, "init", false, "Write a commented "+ConfigFileName+" with the default settings in the current directory, -force overwrites it")
 	flag.Parse()
 
-	files, err := ListMDFiles(wd)
+	if initConfig {
+		if err := WriteConfigScaffold(flag.CommandLine, ConfigFileName, force); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if err := ApplyConfig(flag.CommandLine, config, isFlagSet("config")); err != nil {
+		log.Fatal(err)
+	}
+
+	// Paths may reference environment variables, e.g. -dir=$DOCS_DIR
+	wd = os.ExpandEnv(wd)
+	outFile = os.ExpandEnv(outFile)
+
+	if linkStyle != LinkStyleMarkdown && linkStyle != LinkStyleWiki {
+		log.Fatalf("unknown link style %q", linkStyle)
+	}
+	if drafts != "" && drafts != DraftsExclude && drafts != DraftsAnnotate {
+		log.Fatalf("unknown -mark-drafts value %q", drafts)
+	}
+	switch titleCase {
+	case TitleCaseNone, TitleCaseLower, TitleCaseUpper, TitleCaseTitle:
+	default:
+		log.Fatalf("unknown -title-case value %q", titleCase)
+	}
+	switch multiH1 {
+	case MultipleH1First, MultipleH1Last, MultipleH1Error:
+	default:
+		log.Fatalf("unknown -multiple-h1 value %q", multiH1)
+	}
+	if newline != NewlineSingle && newline != NewlineNone {
+		log.Fatalf("unknown -trailing-newline value %q", newline)
+	}
+	if slugStyle != SlugStyleGitHub && slugStyle != SlugStylePandoc {
+		log.Fatalf("unknown slug style %q", slugStyle)
+	}
+	if hugo && !isFlagSet("sort") {
+		sortBy = SortWeight
+	}
+	if _, ok := SortComparators[sortBy]; !ok {
+		log.Fatalf("unknown sort key %q", sortBy)
+	}
+	if !IsKnownFormat(format) {
+		log.Fatalf("unknown format %q", format)
+	}
+	if update && (outFile == "" || format != FormatMarkdown) {
+		log.Fatal("-update requires -out and the markdown format")
+	}
+	if fixTitles && remoteURL != "" {
+		log.Fatal("-fix-titles cannot be used with -url")
+	}
+	if remoteURL != "" && (since != "" || sidecar != "" || hugo) {
+		// The remote files have no modification time, sidecar files or _index.md handling
+		log.Fatal("-since, -sidecar-ext and -hugo cannot be used with -url")
+	}
+	if concat && (remoteURL != "" || update || byLetter || diffMan != "" || expected != "" || format != FormatMarkdown) {
+		log.Fatal("-concat requires a local -dir and the markdown format, and cannot be used with -update, -index-by-letter, -diff-manifest or -expected")
+	}
+	if lintHeads && remoteURL != "" {
+		log.Fatal("-lint-headings cannot be used with -url")
+	}
+	if byLetter && (update || format != FormatMarkdown) {
+		log.Fatal("-index-by-letter requires the markdown format and cannot be used with -update")
+	}
+	if dirReadmes && (remoteURL != "" || update || outFile != "" || format != FormatMarkdown) {
+		log.Fatal("-dir-readmes requires a local -dir and the markdown format, and cannot be used with -out or -update")
+	}
+	if tee && outFile == "" {
+		log.Fatal("-tee requires -out")
+	}
+	if preview && (dirReadmes || fixTitles) {
+		log.Fatal("-preview cannot be used with -dir-readmes or -fix-titles")
+	}
+	if gzipOut && (outFile == "" || update) {
+		log.Fatal("-gzip requires -out and cannot be used with -update")
+	}
+	if diffMan != "" && (update || format != FormatMarkdown) {
+		log.Fatal("-diff-manifest requires the markdown format and cannot be used with -update")
+	}
+	if expected != "" && (update || format != FormatMarkdown) {
+		log.Fatal("-expected requires the markdown format and cannot be used with -update")
+	}
+	if format == FormatGitHubComment && GitHubRepoFromEnv(ghRepo, ghRef).Name == "" {
+		log.Fatal("the github-comment format requires -github-repo or $GITHUB_REPOSITORY")
+	}
+	if fenced && (update || format != FormatMarkdown) {
+		log.Fatal("-fenced requires the markdown format and cannot be used with -update")
+	}
+
+	strategy, err := ParseTitleStrategy(titleStgy, anyHead, multiH1)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if titleRe != "" {
+		source, err := RegexTitleSource(titleRe)
+		if err != nil {
+			log.Fatalf("invalid -title-regex: %v", err)
+		}
+		// The custom regex is tried before the strategy
+		strategy = append([]TitleSource{source}, strategy...)
+	}
+
+	listOpts := ListOptions{
+		TitleStrategy:   strategy,
+		ReadmeAsSection: readmeSec,
+		Checksums:       format == FormatChecksums,
+		Include:         SplitList(include),
+		Exclude:         SplitList(exclude),
+		MarkDrafts:      drafts,
+		Lang:            lang,
+		Parallel:        parallel,
+		SidecarExt:      sidecar,
+		MaxFiles:        maxFiles,
+		Hugo:            hugo,
+		SkipDirs:        SplitList(skipDirs),
+		SingleH1:        multiH1 == MultipleH1Error,
+	}
+	if selfExcl {
+		listOpts.OutFile = outFile
+	}
+	if since != "" {
+		window, err := ParseSince(since)
+		if err != nil {
+			log.Fatalf("invalid -since: %v", err)
+		}
+		listOpts.ModifiedSince = time.Now().Add(-window)
+	}
+	if exclTitle != "" {
+		listOpts.ExcludeTitle, err = regexp.Compile(exclTitle)
+		if err != nil {
+			log.Fatalf("invalid -exclude-title: %v", err)
+		}
+	}
+	if progress && !quiet {
+		listOpts.Progress = NewProgress(os.Stderr)
+	}
+	// The progress line is ended after every listing, -fix-titles lists the files again
+	listFiles := func() (MDFileInfo, error) {
+		defer listOpts.Progress.Finish()
+		if remoteURL != "" {
+			remote.Headers = headers.Header
+			return ListRemoteMDFiles(remoteURL, listOpts, remote)
+		}
+		return ListMDFiles(wd, listOpts)
+	}
+	files, err := listFiles()
 	if err != nil {
 		log.Fatal(err)
 	}
+	// The paths of the tree are relative to its root, the directory of -dir when it is a file
+	root := ListRoot(wd)
+	if fixTitles {
+		fixed, err := FixTitles(root, files, SplitList(acronyms), dryRun, os.Stderr)
+		if err != nil {
+			log.Fatal(err)
+		}
+		// The fixed files are listed again for their new titles
+		if fixed > 0 && !dryRun {
+			files, err = listFiles()
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+
+	if reqTitles {
+		if untitled := UntitledFiles(files); len(untitled) > 0 {
+			log.Fatalf("files without a title:\n%s", strings.Join(untitled, "\n"))
+		}
+	}
+
+	if lintHeads {
+		if _, err := LintHeadings(root, files, os.Stderr); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if validate {
+		dir := root
+		if remoteURL != "" {
+			dir = ""
+		}
+		if err := Validate(dir, files); err != nil {
+			log.Fatalf("validation failed:\n%v", err)
+		}
+	}
 
-	if title == "" {
+	if title == "" && remoteURL != "" {
+		if u, err := url.Parse(remoteURL); err == nil {
+			files.Title = u.Host
+		}
+	} else if title != "" {
+		files.Title = title
+	} else if files.Title == "" {
 		if wd == "." {
 			wd, _ = os.Getwd()
 		}
 		files.Title = filepath.Base(wd)
+	}
+	if printTree {
+		PrintTree(os.Stderr, files)
+	}
+
+	tocOpts := TocOptions{
+		Indent:            "  ",
+		SortAsc:           sortAsc,
+		Sort:              sortBy,
+		LinkStyle:         linkStyle,
+		Format:            format,
+		Nav:               nav,
+		NavCurrent:        navCurr,
+		TocHeading:        tocHead,
+		SlugStyle:         slugStyle,
+		TaskList:          taskList,
+		Search:            search,
+		CollapseThreshold: collapse,
+		AnchorMode:        anchors || concat,
+		ShowDates:         showDates,
+		DateFormat:        dateFmt,
+		ShowAge:           showAge,
+		HeadingDepth:      FormatHeadingDepths[format],
+		MaxEntries:        maxEntries,
+		NamespaceAnchors:  nsAnchors,
+		ShowHeadingCount:  headCount,
+		Wrap:              wrap,
+		GitHub:            GitHubRepoFromEnv(ghRepo, ghRef),
+		Compact:           compact,
+		EditBaseURL:       editBase,
+		SectionJumplist:   jumplist,
+	}
+	if headDepth >= 0 {
+		tocOpts.HeadingDepth = headDepth
+	}
+	if format == FormatGitHubComment {
+		tocOpts.GitHub.Path = ghPath
+		if !isFlagSet("github-path") && remoteURL == "" {
+			tocOpts.GitHub.Path = GitPrefix(root)
+		}
+	}
+	if orderFile != "" {
+		tocOpts.Order, err = LoadOrderFile(orderFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	tocOpts.TableColumns, err = ParseTableColumns(tableCols)
+	if err != nil {
+		log.Fatalf("invalid -table-columns: %v", err)
+	}
+	if entryFmt != "" {
+		tocOpts.EntryFormat, err = ParseEntryFormat(entryFmt)
+		if err != nil {
+			log.Fatalf("invalid -entry-format: %v", err)
+		}
+	}
+	if dirsOnly {
+		files = DirsOnly(files)
+	}
+	if humanize {
+		files = HumanizeDirs(files, SplitList(acronyms))
+	}
+	if titleCase != TitleCaseNone {
+		files = CaseTitles(files, titleCase)
+	}
+	if keys := SplitList(iconKeys); len(keys) > 0 {
+		files = PrefixIcons(files, keys)
+	}
+	if maxDepth > 0 {
+		files = LimitDepth(files, maxDepth)
+	}
+	// The PDF outline is always numbered
+	if secNums && format != FormatPDFOutline {
+		files = NumberSections(files, "", tocOpts)
+	}
+
+	if dirReadmes {
+		if err := WriteDirReadmes(root, files, tocOpts, force, os.Stderr); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// The plain Markdown TOC is streamed to the output file rather than built in memory
+	if outFile != "" && format == FormatMarkdown && !update && !byLetter && expected == "" && diffMan == "" && !fenced &&
+		prepend == "" && appendF == "" && postCmd == "" && !clipboard && !concat && !gzipOut && !preview {
+		if err := StreamToc(outFile, files, tocOpts, tee, newline); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	var toc string
+	if update {
+		toc, err = UpdateToc(outFile, files, tocOpts)
+		if err != nil {
+			log.Fatal(err)
+		}
+	} else if byLetter {
+		toc = CreateLetterIndex(files, tocOpts)
+	} else if diffMan != "" {
+		baseline, err := LoadManifest(diffMan)
+		if err != nil {
+			log.Fatal(err)
+		}
+		toc = CreateManifestDiff(files, baseline, tocOpts)
+	} else if expected != "" {
+		topics, err := LoadExpectedTopics(expected)
+		if err != nil {
+			log.Fatal(err)
+		}
+		toc = CreateCoverage(files, topics, tocOpts)
 	} else {
-		files.Title = title
+		toc = RenderToc(files, tocOpts)
+	}
+	if concat {
+		document, err := CreateConcatenation(root, files, tocOpts)
+		if err != nil {
+			log.Fatal(err)
+		}
+		toc += document
+	}
+	if fenced {
+		toc = FenceToc(toc)
+	}
+	toc, err = ComposeOutput(prepend, toc, appendF)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if postCmd != "" {
+		toc, err = ShellPostProcessor(postCmd)(toc)
+		if err != nil {
+			log.Fatal(err)
+		}
 	}
 
-	toc := CreateTocTree(files, "  ", sortAsc)
+	toc = ApplyTrailingNewline(toc, newline)
+	if preview {
+		var current []string
+		if lines, err := ReadLines(outFile); outFile != "" && !gzipOut && err == nil {
+			// An empty file differs from every line
+			current = append([]string{}, lines...)
+		}
+		WritePreview(os.Stderr, toc, current)
+		return
+	}
+	if clipboard {
+		if err := CopyToClipboard(toc); err != nil {
+			log.Fatalf("copying to the clipboard: %v", err)
+		}
+	}
 
 	if outFile != "" {
-		err = os.WriteFile(outFile, []byte(toc), 0644)
+		if gzipOut {
+			err = WriteGzipFile(GzipPath(outFile), []byte(toc))
+		} else {
+			err = os.WriteFile(outFile, []byte(toc), 0644)
+		}
 		if err != nil {
 			log.Fatal(err)
 		}
-	} else {
-		fmt.Println(toc)
+		if tee {
+			fmt.Print(toc)
+		}
+	} else if !clipboard {
+		fmt.Print(toc)
+	}
+}
+
+// FenceToc wraps the TOC in a `markdown` code fence so it is displayed literally instead of rendered.
+// The fence is longer than any run of backticks in the TOC, e.g. from a title, so it cannot be closed early.
+//
+// Parameters:
+// - toc: the generated TOC.
+//
+// Returns:
+// - string: the fenced TOC.
+func FenceToc(toc string) string {
+	fence := "```"
+	for strings.Contains(toc, fence) {
+		fence += "`"
+	}
+	return fence + "markdown\n" + strings.TrimRight(toc, "\n") + "\n" + fence + "\n"
+}
+
+// sinceUnitRegex matches the leading day or week count of a `-since` duration, e.g. `7d` or `2w`.
+var sinceUnitRegex = regexp.MustCompile(`^(\d+)([dw])`)
+
+// ParseSince parses a duration like time.ParseDuration does, with the `d` and `w` units for
+// days and weeks in front of the others, e.g. `7d`, `2w` or `1d12h`.
+//
+// Parameters:
+// - value: the duration.
+//
+// Returns:
+// - time.Duration: the parsed duration.
+// - error: an error if the duration is invalid.
+func ParseSince(value string) (time.Duration, error) {
+	var total time.Duration
+	rest := value
+	for {
+		match := sinceUnitRegex.FindStringSubmatch(rest)
+		if match == nil {
+			break
+		}
+		n, err := strconv.Atoi(match[1])
+		if err != nil {
+			return 0, err
+		}
+		unit := 24 * time.Hour
+		if match[2] == "w" {
+			unit *= 7
+		}
+		total += time.Duration(n) * unit
+		rest = rest[len(match[0]):]
+	}
+	if rest == "" && total > 0 {
+		return total, nil
+	}
+	d, err := time.ParseDuration(rest)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+	return total + d, nil
+}
+
+// isFlagSet reports whether the flag with the given name was given on the command line.
+func isFlagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// ComposeOutput surrounds the generated TOC with the content of the prepend and append files.
+//
+// Each non-empty part is separated from the next one by a blank line. Empty file paths are skipped.
+//
+// Parameters:
+// - prependPath: the path of the file inserted before the TOC, or an empty string.
+// - toc: the generated TOC.
+// - appendPath: the path of the file inserted after the TOC, or an empty string.
+//
+// Returns:
+// - string: the composed output.
+// - error: an error if one of the files could not be read.
+func ComposeOutput(prependPath, toc, appendPath string) (string, error) {
+	parts := []string{}
+	if prependPath != "" {
+		content, err := os.ReadFile(prependPath)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, strings.TrimRight(string(content), "\n"))
+	}
+	parts = append(parts, strings.TrimRight(toc, "\n"))
+	if appendPath != "" {
+		content, err := os.ReadFile(appendPath)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, strings.TrimRight(string(content), "\n"))
+	}
+	if len(parts) == 1 {
+		return toc, nil
 	}
+	return strings.Join(parts, "\n\n") + "\n", nil
+}
+
+// SplitList splits a comma-separated flag value into its trimmed, non-empty items.
+//
+// Parameters:
+// - value: the value of the flag.
+//
+// Returns:
+// - []string: the items of the list.
+func SplitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// ListRoot returns the directory the paths of the tree listed by ListMDFiles from dirPath are
+// relative to: dirPath itself, or its directory when dirPath is a single file.
+//
+// Parameters:
+// - dirPath: the path the tree is listed from.
+//
+// Returns:
+// - string: the root directory of the tree.
+func ListRoot(dirPath string) string {
+	if info, err := os.Stat(FixLongPath(dirPath)); err == nil && !info.IsDir() {
+		return filepath.Dir(dirPath)
+	}
+	return dirPath
 }
 
 // ListMDFiles lists all the Markdown files in the given path and its subdirectories.
 //
-// It takes a string parameter `dirPath` which represents the directory path to search for Markdown files.
+// It takes a string parameter `dirPath` which represents the directory path to search for Markdown files,
+// and the options `opts` which control how the files are discovered.
+// If `dirPath` is a Markdown file, the tree only holds this file and the root is titled after it, see ListRoot.
 // The function returns a `MDFileInfo` struct which represents the root directory and its descendants,
 // and an error if any occurred during the file walk.
 //
@@ -74,7 +853,13 @@ func main() {
 // - `Level`: the level of indentation for the file or directory
 // - `Title`: the title of the Markdown file
 // - `Path`: the full path of the file or directory
-func ListMDFiles(dirPath string) (MDFileInfo, error) {
+// - `IndexPath`: the path of the README.md or index.md of a directory, if it has one
+// - `LinkPath`: the path a directory links to in the TOC, if it is rendered as a link
+// - `ModTime`: the last modification time of the file or directory
+// - `Checksum`: the hex-encoded SHA-256 of a file, if requested in the options
+// - `Frontmatter`: the top-level values of the YAML frontmatter of a file, or of the _index.md of a directory
+// - `HeadingCount`: the number of headings of a file, H1 to H6
+func ListMDFiles(dirPath string, opts ListOptions) (MDFileInfo, error) {
 	root := MDFileInfo{
 		IsDir:    true,
 		Children: make(map[string]MDFileInfo),
@@ -82,120 +867,1047 @@ func ListMDFiles(dirPath string) (MDFileInfo, error) {
 		Title:    "",
 		Path:     ".",
 	}
-	err := filepath.Walk(dirPath,
-		func(path string, info os.FileInfo, err error) error {
-			if err != nil {
-				return err
-			}
-			// We get Markdown files only
-			if !info.IsDir() && info.Name() != "README.md" && filepath.Ext(path) == ".md" {
-				relPath := strings.Replace(path, dirPath, ".", 1)
-				dirs := strings.Split(filepath.Dir(relPath), "/")
-				p := root
-				for _, d := range dirs {
-					if d == "." {
-						continue
-					}
-					if _, ok := p.Children[d]; !ok {
-						p.Children[d] = MDFileInfo{
-							IsDir:    true,
-							Children: make(map[string]MDFileInfo),
-							Level:    p.Level + 1,
-							Title:    d,
-							Path:     url.PathEscape(filepath.Join(p.Path, d)),
-						}
-					}
-					p = p.Children[d]
-				}
-				p.Children[info.Name()] = MDFileInfo{
-					IsDir: false,
-					Level: p.Level + 1,
-					Title: GetMDTitle(path),
-					Path:  url.PathEscape(relPath),
+	dirPath = FixLongPath(dirPath)
+	// A single file is listed on its own, relative to its directory
+	if info, err := os.Stat(dirPath); err == nil && !info.IsDir() {
+		l := &mdLister{root: root, dirPath: filepath.Dir(dirPath), opts: opts}
+		relPath := "." + string(filepath.Separator) + info.Name()
+		file, ok, err := l.readFile(dirPath, relPath, info)
+		if ok {
+			root.Title = file.Title
+			AddFile(root, relPath, file, nil)
+		}
+		return root, err
+	}
+	ignorePatterns, err := LoadIgnorePatterns(filepath.Join(dirPath, IgnoreFileName))
+	if err != nil {
+		return root, err
+	}
+	l := &mdLister{
+		root:           root,
+		dirPath:        dirPath,
+		opts:           opts,
+		ignorePatterns: ignorePatterns,
+		outRel:         relOutFile(dirPath, opts.OutFile),
+	}
+	if opts.Parallel > 1 {
+		err = l.walkParallel()
+	} else {
+		err = filepath.Walk(dirPath,
+			func(path string, info os.FileInfo, err error) error {
+				if err != nil {
+					return err
 				}
-			}
-			return nil
-		})
+				return l.visit(path, info)
+			})
+	}
 	if err != nil {
 		return root, err
 	}
 	return root, nil
 }
 
-// GetMDTitle retrieves the title of a Markdown file, the title of the file is the first H1 header.
-//
-// It takes a filePath string parameter, which represents the path of the Markdown file.
-// The function opens the file, reads its contents line by line, and searches for an H1 header.
-// If an H1 header is found, it returns the text inside the header.
-// If no H1 header is found or an error occurs while opening the file, it returns an empty string.
-//
-// Parameters:
-// - filePath: the path of the Markdown file.
-//
-// Return type:
-// - string: the title of the Markdown file, or an empty string if no title is found or an error occurs.
-func GetMDTitle(filePath string) string {
-	file, err := os.Open(filePath)
+// mdLister builds the tree of the Markdown files found while walking a directory.
+// Its visit method is safe for concurrent use.
+type mdLister struct {
+	root           MDFileInfo
+	dirPath        string
+	opts           ListOptions
+	ignorePatterns []string
+	outRel         string       // the path of the output file relative to dirPath, if it is in dirPath
+	found          atomic.Int64 // the number of Markdown files found, counted for opts.MaxFiles
+	mu             sync.Mutex   // guards the children of the tree
+}
+
+// TooManyFilesError returns the error aborting a scan which found more than the maximum number of files.
+func TooManyFilesError(source string, maxFiles int) error {
+	return fmt.Errorf("more than %d Markdown files found in %s, check the directory or raise -max-files", maxFiles, source)
+}
+
+// relOutFile returns the path of the output file relative to the directory, or an empty string
+// if there is no output file or it is outside of the directory. The directory is in the form
+// returned by FixLongPath, the output file is turned into the same form before comparing them.
+func relOutFile(dirPath, outFile string) string {
+	if outFile == "" {
+		return ""
+	}
+	absDir, err := filepath.Abs(dirPath)
 	if err != nil {
 		return ""
 	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	h1Regex := regexp.MustCompile(`^#\s+(.*)$`)
-
-	for scanner.Scan() {
-		line := scanner.Text()
-		if h1Regex.MatchString(line) {
-			return h1Regex.FindStringSubmatch(line)[1]
-		}
+	absOut, err := filepath.Abs(FixLongPath(outFile))
+	if err != nil {
+		return ""
 	}
-
-	return ""
+	rel, err := filepath.Rel(absDir, absOut)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return ""
+	}
+	return rel
 }
 
-// CreateTocTree generates a table of contents (TOC) tree for the given MDFileInfo.
-//
-// Parameters:
-// - md: the MDFileInfo object representing the file or directory.
-// - indent: the string used for indentation in the TOC.
-// - sortAsc: a boolean indicating whether the TOC should be sorted in ascending order.
-//
-// Returns:
-// - string: the generated TOC tree.
-func CreateTocTree(md MDFileInfo, indent string, sortAsc bool) string {
-	var (
-		toc string
-	)
-	switch md.Level {
-	case 0:
-		toc = "# " + md.Title + "\n"
-	case 1:
-		if md.IsDir {
-			toc = fmt.Sprintf("\n## %s\n\n", md.Title)
-		} else {
-			toc = fmt.Sprintf("\n## [%s](%s)\n\n", md.Title, md.Path)
+// visit adds the file at the given path to the tree if it is a Markdown file which should be listed.
+// It returns filepath.SkipDir for the directories which must not be walked.
+func (l *mdLister) visit(path string, info os.FileInfo) error {
+	rel, err := filepath.Rel(l.dirPath, path)
+	if err != nil {
+		return err
+	}
+	relPath := "." + string(filepath.Separator) + rel
+	if rel == "." {
+		relPath = "."
+	}
+	slashPath := strings.TrimPrefix(filepath.ToSlash(relPath), "./")
+	// The generated TOC must not list itself on the next run
+	if l.outRel != "" && rel == l.outRel {
+		return nil
+	}
+	if info.IsDir() && rel != "." && IsSkippedDir(info.Name(), l.opts.SkipDirs) {
+		return filepath.SkipDir
+	}
+	if MatchesAnyPattern(slashPath, info.IsDir(), l.ignorePatterns) || MatchesAnyPattern(slashPath, info.IsDir(), l.opts.Exclude) {
+		if info.IsDir() {
+			return filepath.SkipDir
+		}
+		return nil
+	}
+	if !info.IsDir() && len(l.opts.Include) > 0 && !MatchesAnyPattern(slashPath, false, l.opts.Include) {
+		return nil
+	}
+	// We get Markdown files only
+	if info.IsDir() || filepath.Ext(path) != ".md" {
+		return nil
+	}
+	if l.opts.Hugo && info.Name() == HugoIndexFileName {
+		// The _index.md is the link of its section rather than an entry
+		if rel != HugoIndexFileName {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			AddDirs(l.root, filepath.Dir(relPath), func(parent MDFileInfo, name, relDir string) MDFileInfo {
+				return newDirInfo(parent, name, filepath.Join(l.dirPath, relDir), relDir, l.opts)
+			})
+		}
+		return nil
+	}
+	isReadme := info.Name() == "README.md"
+	if isReadme && !l.opts.ReadmeAsSection {
+		return nil
+	}
+	if l.opts.Lang != "" {
+		if lang := FileLang(info.Name()); lang != "" && !strings.EqualFold(lang, l.opts.Lang) {
+			return nil
+		}
+	}
+	if !l.opts.ModifiedSince.IsZero() && info.ModTime().Before(l.opts.ModifiedSince) {
+		return nil
+	}
+	if l.opts.MaxFiles > 0 && l.found.Add(1) > int64(l.opts.MaxFiles) {
+		return TooManyFilesError(l.dirPath, l.opts.MaxFiles)
+	}
+	var file MDFileInfo
+	if !isReadme {
+		var ok bool
+		if file, ok, err = l.readFile(path, relPath, info); !ok {
+			return err
+		}
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	newDir := func(parent MDFileInfo, name, relDir string) MDFileInfo {
+		return newDirInfo(parent, name, filepath.Join(l.dirPath, relDir), relDir, l.opts)
+	}
+	// With ReadmeAsSection, the README is the link of its directory rather than an entry
+	if isReadme {
+		AddDirs(l.root, filepath.Dir(relPath), newDir)
+		return nil
+	}
+	AddFile(l.root, relPath, file, newDir)
+	return nil
+}
+
+// readFile reads a Markdown file once, for its title, its frontmatter and its checksum.
+// It returns false if the file is a draft or has a title which are not listed, and an error
+// if it cannot be read or has several H1 headers with opts.SingleH1.
+func (l *mdLister) readFile(path, relPath string, info os.FileInfo) (MDFileInfo, bool, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return MDFileInfo{}, false, err
+	}
+	file, err := newFileInfo(path, relPath, content, l.opts)
+	if err != nil {
+		return file, false, err
+	}
+	file.ModTime = info.ModTime()
+	if l.opts.SidecarExt != "" {
+		if title := SidecarTitle(path, l.opts.SidecarExt); title != "" {
+			file.Title = title
+		}
+	}
+	l.opts.Progress.Add()
+	return file, keepFile(&file, l.opts), nil
+}
+
+// newFileInfo creates the MDFileInfo of a Markdown file from its content, for the local and the
+// remote listings.
+//
+// Parameters:
+// - source: the path or URL of the file, for the errors.
+// - relPath: the path of the file relative to the root directory, with a leading `./`.
+// - content: the content of the file.
+// - opts: the options used to discover the Markdown files.
+//
+// Returns:
+// - MDFileInfo: the file, without its modification time.
+// - error: an error if the file has several H1 headers and opts.SingleH1 is set.
+func newFileInfo(source, relPath string, content []byte, opts ListOptions) (MDFileInfo, error) {
+	lines := SplitLines(content)
+	file := MDFileInfo{
+		Name:        filepath.Base(relPath),
+		IsDir:       false,
+		Title:       ResolveTitleFromLines(lines, opts.TitleStrategy),
+		Path:        EscapePath(relPath),
+		Frontmatter: ParseFrontmatter(lines),
+		// The headings and words are counted in the same scan
+		HeadingCount: CountHeadings(lines),
+		WordCount:    CountWords(lines),
+	}
+	if opts.SingleH1 && len(H1Titles(lines)) > 1 {
+		return file, fmt.Errorf("%s: multiple H1 headers", source)
+	}
+	if opts.Checksums {
+		file.Checksum = fmt.Sprintf("%x", sha256.Sum256(content))
+	}
+	return file, nil
+}
+
+// keepFile reports whether a file is listed according to the exclude-title and drafts options,
+// and annotates the title of the drafts with DraftsAnnotate.
+//
+// Parameters:
+// - file: the file, whose title may be changed.
+// - opts: the options used to discover the Markdown files.
+//
+// Returns:
+// - bool: whether the file is listed.
+func keepFile(file *MDFileInfo, opts ListOptions) bool {
+	if opts.ExcludeTitle != nil && opts.ExcludeTitle.MatchString(file.Title) {
+		return false
+	}
+	if IsDraft(file.Frontmatter) {
+		switch opts.MarkDrafts {
+		case DraftsExclude:
+			return false
+		case DraftsAnnotate:
+			file.Title += " (draft)"
+		}
+	}
+	return true
+}
+
+// AddDirs makes sure the directories of the given relative path exist in the tree, creating
+// the missing ones with newDir.
+//
+// Parameters:
+// - root: the MDFileInfo object representing the root directory.
+// - relDir: the path of the directory relative to the root directory.
+// - newDir: the function creating the MDFileInfo of a directory from its parent, its name and its relative path.
+//
+// Returns:
+// - MDFileInfo: the directory at relDir.
+func AddDirs(root MDFileInfo, relDir string, newDir func(parent MDFileInfo, name, relDir string) MDFileInfo) MDFileInfo {
+	p := root
+	path := ""
+	for _, d := range strings.Split(filepath.ToSlash(relDir), "/") {
+		if d == "." || d == "" {
+			continue
+		}
+		path = filepath.Join(path, d)
+		if _, ok := p.Children[d]; !ok {
+			p.Children[d] = newDir(p, d, path)
+		}
+		p = p.Children[d]
+	}
+	return p
+}
+
+// AddFile adds a file to the tree under its directories, creating the missing ones with newDir.
+// The level of the file is set from its depth.
+//
+// Parameters:
+// - root: the MDFileInfo object representing the root directory.
+// - relPath: the path of the file relative to the root directory.
+// - file: the MDFileInfo object representing the file.
+// - newDir: the function creating the MDFileInfo of a directory, see AddDirs.
+func AddFile(root MDFileInfo, relPath string, file MDFileInfo, newDir func(parent MDFileInfo, name, relDir string) MDFileInfo) {
+	p := AddDirs(root, filepath.Dir(relPath), newDir)
+	file.Level = p.Level + 1
+	p.Children[file.Name] = file
+}
+
+// walkParallel walks the directory like filepath.Walk, but walks the subdirectories concurrently
+// with at most opts.Parallel goroutines. The order in which the files are visited is not
+// deterministic, which does not matter as the children are sorted when rendering.
+func (l *mdLister) walkParallel() error {
+	info, err := os.Lstat(l.dirPath)
+	if err != nil {
+		return err
+	}
+	if err := l.visit(l.dirPath, info); err != nil || !info.IsDir() {
+		if err == filepath.SkipDir {
+			return nil
+		}
+		return err
+	}
+	var g errgroup.Group
+	g.SetLimit(l.opts.Parallel)
+	var walkDir func(dir string) error
+	walkDir = func(dir string) error {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			path := filepath.Join(dir, entry.Name())
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			err = l.visit(path, info)
+			if err == filepath.SkipDir {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				// Walk the subdirectory in this goroutine when no other one is available,
+				// waiting for one could dead-lock as all of them may be waiting too
+				if !g.TryGo(func() error { return walkDir(path) }) {
+					if err := walkDir(path); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		return nil
+	}
+	if err := walkDir(l.dirPath); err != nil {
+		g.Wait()
+		return err
+	}
+	return g.Wait()
+}
+
+// newDirInfo creates the MDFileInfo of a directory, child of parent.
+//
+// The title of the directory is its name, unless it is overridden by a title file. With
+// opts.ReadmeAsSection, a directory containing a README.md is titled after the README and links to it.
+//
+// Parameters:
+// - parent: the MDFileInfo of the parent directory.
+// - name: the name of the directory.
+// - osDir: the path of the directory.
+// - relDir: the path of the directory relative to the root directory.
+// - opts: the options used to discover the Markdown files.
+//
+// Returns:
+// - MDFileInfo: the directory node, without children.
+func newDirInfo(parent MDFileInfo, name, osDir, relDir string, opts ListOptions) MDFileInfo {
+	dir := MDFileInfo{
+		Name:      name,
+		IsDir:     true,
+		Children:  make(map[string]MDFileInfo),
+		Level:     parent.Level + 1,
+		Title:     GetDirTitle(osDir),
+		Path:      EscapePath("./" + relDir),
+		IndexPath: GetDirIndex(osDir, relDir),
+	}
+	if info, err := os.Stat(osDir); err == nil {
+		dir.ModTime = info.ModTime()
+	}
+	if lines, err := ReadLines(filepath.Join(osDir, HugoIndexFileName)); err == nil {
+		dir.Frontmatter = ParseFrontmatter(lines)
+		// A Hugo section is titled by and links to its _index.md
+		if opts.Hugo {
+			dir.LinkPath = EscapePath("./" + filepath.Join(relDir, HugoIndexFileName))
+			if dir.Title == "" {
+				dir.Title = dir.Frontmatter["title"]
+			}
+			if dir.Title == "" {
+				dir.Title = ResolveTitleFromLines(lines, opts.TitleStrategy)
+			}
+		}
+	}
+	if opts.ReadmeAsSection {
+		readme := filepath.Join(osDir, "README.md")
+		if _, err := os.Stat(readme); err == nil {
+			dir.LinkPath = EscapePath("./" + filepath.Join(relDir, "README.md"))
+			if dir.Title == "" {
+				dir.Title = ResolveTitle(readme, opts.TitleStrategy)
+			}
+		}
+	}
+	if dir.Title == "" {
+		dir.Title = name
+	}
+	return dir
+}
+
+// GetDirIndex returns the escaped path of the index file of a directory, which is its README.md
+// or its index.md.
+//
+// Parameters:
+// - dirPath: the path of the directory.
+// - relDir: the path of the directory relative to the root directory.
+//
+// Returns:
+// - string: the escaped relative path of the index file, or an empty string if the directory has none.
+func GetDirIndex(dirPath, relDir string) string {
+	for _, name := range DirIndexFileNames {
+		if info, err := os.Stat(filepath.Join(dirPath, name)); err == nil && !info.IsDir() {
+			return EscapePath("./" + filepath.Join(relDir, name))
+		}
+	}
+	return ""
+}
+
+// GetDirTitle retrieves the title override of a directory, which is the first non-blank line
+// of a `.title` or `_title` file in the directory.
+//
+// Parameters:
+// - dirPath: the path of the directory.
+//
+// Returns:
+// - string: the title override, or an empty string if the directory has none.
+func GetDirTitle(dirPath string) string {
+	for _, name := range DirTitleFileNames {
+		lines, err := ReadLines(filepath.Join(dirPath, name))
+		if err != nil {
+			continue
+		}
+		for _, line := range lines {
+			if line = strings.TrimSpace(line); line != "" {
+				return line
+			}
+		}
+	}
+	return ""
+}
+
+// LoadIgnorePatterns reads the glob patterns from the given ignore file.
+//
+// Blank lines and lines starting with `#` are skipped. A missing ignore file is not an error,
+// it simply yields no patterns.
+//
+// Parameters:
+// - filePath: the path of the ignore file.
+//
+// Returns:
+// - []string: the patterns listed in the file.
+// - error: an error if the file exists but could not be read.
+func LoadIgnorePatterns(filePath string) ([]string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	return patterns, scanner.Err()
+}
+
+// IsSkippedDir reports whether a directory of the given name is left out, see ListOptions.SkipDirs.
+func IsSkippedDir(name string, skipDirs []string) bool {
+	for _, skipped := range skipDirs {
+		if name == skipped {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchesAnyPattern reports whether the given path matches one of the glob patterns.
+//
+// Patterns are matched against the path relative to the root directory. A pattern without
+// a slash also matches the base name at any depth, and a pattern ending with a slash
+// only matches directories.
+//
+// Parameters:
+// - relPath: the slash-separated path relative to the root directory.
+// - isDir: a boolean indicating whether the path is a directory.
+// - patterns: the glob patterns, e.g. loaded by LoadIgnorePatterns.
+//
+// Returns:
+// - bool: true if the path matches one of the patterns.
+func MatchesAnyPattern(relPath string, isDir bool, patterns []string) bool {
+	if relPath == "." || relPath == "" {
+		return false
+	}
+	for _, pattern := range patterns {
+		if strings.HasSuffix(pattern, "/") {
+			if !isDir {
+				continue
+			}
+			pattern = strings.TrimSuffix(pattern, "/")
+		}
+		// A pattern with a slash, e.g. /notes.md, is anchored to the root
+		anchored := strings.Contains(pattern, "/")
+		pattern = strings.TrimPrefix(pattern, "/")
+		if ok, _ := path.Match(pattern, relPath); ok {
+			return true
+		}
+		if !anchored {
+			if ok, _ := path.Match(pattern, path.Base(relPath)); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// RenderToc renders the TOC of the given MDFileInfo in the format selected in the options.
+//
+// Parameters:
+// - md: the MDFileInfo object representing the root directory.
+// - opts: the options used to render the TOC.
+//
+// Returns:
+// - string: the rendered TOC.
+func RenderToc(md MDFileInfo, opts TocOptions) string {
+	switch opts.Format {
+	case FormatHTML:
+		return CreateHTMLTree(md, opts)
+	case FormatBlockquote:
+		return CreateBlockquoteTree(md, opts)
+	case FormatJSONLines:
+		return CreateJSONLines(md, opts)
+	case FormatOPML:
+		return CreateOPML(md, opts)
+	case FormatBreadcrumbs:
+		return CreateBreadcrumbs(md, opts)
+	case FormatText:
+		return CreateTextTree(md, opts)
+	case FormatChecksums:
+		return CreateChecksums(md, opts)
+	case FormatAsciiDoc:
+		return CreateAsciiDoc(md, opts)
+	case FormatConfluence:
+		return CreateConfluence(md, opts)
+	case FormatMkDocsNav:
+		return CreateMkDocsNav(md, opts)
+	case FormatFlat:
+		return CreateFlatList(md, opts)
+	case FormatPDFOutline:
+		return CreatePDFOutline(md, opts)
+	case FormatManifest:
+		return CreateManifest(md, opts)
+	case FormatDOT:
+		return CreateDOT(md, opts)
+	case FormatYAML:
+		return CreateYAML(md, opts)
+	case FormatGitHubComment:
+		return CreateTocTree(md, opts)
+	case FormatHTMLTable:
+		return CreateHTMLTable(md, opts)
+	case FormatInline:
+		return CreateInlineNav(md, opts)
+	case FormatDocusaurus:
+		return CreateDocusaurusSidebar(md, opts)
+	default:
+		return CreateTocTree(md, opts)
+	}
+}
+
+// CreateTocTree generates a table of contents (TOC) tree for the given MDFileInfo.
+//
+// The tree is traversed with an explicit stack rather than recursion, so that pathologically
+// deep trees cannot exhaust the call stack.
+//
+// Parameters:
+// - md: the MDFileInfo object representing the file or directory.
+// - opts: the options used to render the TOC.
+//
+// Returns:
+// - string: the generated TOC tree.
+func CreateTocTree(md MDFileInfo, opts TocOptions) string {
+	var sb strings.Builder
+	// A strings.Builder never fails
+	_ = WriteTocTree(&sb, md, opts)
+	return sb.String()
+}
+
+// WriteTocTree writes the TOC tree generated by CreateTocTree to w as it is rendered, so that
+// the TOC of a very large tree is never held in memory.
+//
+// Parameters:
+// - w: the writer the TOC is written to.
+// - md: the MDFileInfo object representing the file or directory.
+// - opts: the options used to render the TOC.
+//
+// Returns:
+// - error: the first error returned by w.
+func WriteTocTree(w io.Writer, md MDFileInfo, opts TocOptions) error {
+	// An item of the stack is either a node to render or a text to write as is
+	type tocItem struct {
+		node MDFileInfo
+		text string
+	}
+	// The errors of a bufio.Writer are sticky, they are returned by Flush
+	bw := bufio.NewWriter(w)
+	stack := []tocItem{{node: md}}
+	for len(stack) > 0 {
+		item := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if item.text != "" {
+			bw.WriteString(item.text)
+			continue
+		}
+		node := item.node
+		bw.WriteString(tocEntry(node, opts))
+		if node.Level == 1 && opts.HeadingDepth >= 1 && opts.CollapseThreshold > 0 {
+			if count := CountEntries(node); count > opts.CollapseThreshold {
+				fmt.Fprintf(bw, "<details>\n<summary>Show %d entries</summary>\n\n", count)
+				stack = append(stack, tocItem{text: "\n</details>\n"})
+			}
 		}
+		keys := SortedChildKeys(node, opts)
+		if node.Level > 0 && opts.MaxEntries > 0 && len(keys) > opts.MaxEntries {
+			stack = append(stack, tocItem{text: moreEntry(node.Level+1, len(keys)-opts.MaxEntries, opts)})
+			keys = keys[:opts.MaxEntries]
+		}
+		for i := len(keys) - 1; i >= 0; i-- {
+			stack = append(stack, tocItem{node: node.Children[keys[i]]})
+		}
+	}
+	return bw.Flush()
+}
+
+// StreamToc writes the Markdown TOC to the output file with WriteTocTree, and to stdout as well with tee.
+//
+// Parameters:
+// - outFile: the path of the output file.
+// - md: the MDFileInfo object representing the root directory.
+// - opts: the options used to render the TOC.
+// - tee: whether the TOC is also written to stdout.
+// - newline: the trailing newline policy, NewlineSingle or NewlineNone.
+//
+// Returns:
+// - error: an error if the file could not be written.
+func StreamToc(outFile string, md MDFileInfo, opts TocOptions, tee bool, newline string) error {
+	file, err := os.Create(outFile)
+	if err != nil {
+		return err
+	}
+	var w io.Writer = file
+	if tee {
+		w = io.MultiWriter(file, os.Stdout)
+	}
+	nw := &newlineWriter{w: w, policy: newline}
+	if err := WriteTocTree(nw, md, opts); err != nil {
+		file.Close()
+		return err
+	}
+	if err := nw.Close(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// CountEntries returns the number of descendants of md, files and directories.
+//
+// Parameters:
+// - md: the MDFileInfo object representing the directory.
+//
+// Returns:
+// - int: the number of entries under md.
+func CountEntries(md MDFileInfo) int {
+	count := 0
+	for _, child := range md.Children {
+		count += 1 + CountEntries(child)
+	}
+	return count
+}
+
+// tocEntry renders the line of a single node of the Markdown TOC, without its children.
+// The nodes down to opts.HeadingDepth are headings, `##` for the first level, the deeper ones are list items.
+// The headings are surrounded by blank lines, unless opts.Compact is set.
+func tocEntry(md MDFileInfo, opts TocOptions) string {
+	switch {
+	case md.Level == 0:
+		if opts.HeadingDepth == 0 && !opts.Compact {
+			// The list starts right under the title
+			return RootHeading(md, opts) + "\n"
+		}
+		if opts.SectionJumplist {
+			return RootHeading(md, opts) + SectionJumplist(md, opts)
+		}
+		return RootHeading(md, opts)
+	case md.Level <= opts.HeadingDepth:
+		if opts.TaskList && !md.IsDir {
+			if opts.Compact {
+				return fmt.Sprintf("- %s%s\n", TaskBox(md), EntryText(md, opts))
+			}
+			return fmt.Sprintf("\n- %s%s\n", TaskBox(md), EntryText(md, opts))
+		}
+		if opts.Compact {
+			// An ATX heading needs no blank line around it, even right after a list item
+			return fmt.Sprintf("%s %s\n", HeadingMarker(md.Level+1), EntryText(md, opts))
+		}
+		return fmt.Sprintf("\n%s %s\n\n", HeadingMarker(md.Level+1), EntryText(md, opts))
 	default:
-		if md.IsDir {
-			toc = fmt.Sprintf("%s- %s\n", strings.Repeat(indent, md.Level-2), md.Title)
+		box := ""
+		if opts.TaskList && !md.IsDir {
+			box = TaskBox(md)
+		}
+		return fmt.Sprintf("%s%s%s%s\n", strings.Repeat(opts.Indent, md.Level-opts.HeadingDepth-1), ListMarker, box, EntryText(md, opts))
+	}
+}
+
+// moreEntry renders the `... and N more` line ending the entries of a section cut at opts.MaxEntries,
+// as a list item at the level of the entries, or as a paragraph among headings.
+func moreEntry(level, count int, opts TocOptions) string {
+	if level <= opts.HeadingDepth {
+		return fmt.Sprintf("\n... and %d more\n", count)
+	}
+	return fmt.Sprintf("%s%s... and %d more\n", strings.Repeat(opts.Indent, level-opts.HeadingDepth-1), ListMarker, count)
+}
+
+// FormatHeadingDepths maps the formats rendering their first levels as headings to the default
+// number of these levels, which the `-heading-depth` flag overrides. The other formats have none.
+var FormatHeadingDepths = map[string]int{
+	FormatMarkdown:      1,
+	FormatPDFOutline:    6,
+	FormatGitHubComment: 1,
+}
+
+// HeadingMarker returns the `#` marker of a Markdown heading of the given level, capped at 6,
+// the deepest level supported by Markdown.
+func HeadingMarker(level int) string {
+	if level > 6 {
+		level = 6
+	}
+	return strings.Repeat("#", level)
+}
+
+// ListMarker is the bullet of the Markdown list items.
+const ListMarker = "- "
+
+// TaskBox renders the task-list checkbox of a file, checked when its frontmatter has `reviewed: true`.
+//
+// Parameters:
+// - md: the MDFileInfo object representing the file.
+//
+// Returns:
+// - string: `[x] ` or `[ ] `.
+func TaskBox(md MDFileInfo) string {
+	if reviewed, err := strconv.ParseBool(md.Frontmatter["reviewed"]); err == nil && reviewed {
+		return "[x] "
+	}
+	return "[ ] "
+}
+
+// EntryText renders the text of a TOC entry: the link to a file, the link of a directory
+// which has a LinkPath, or the title of any other directory.
+//
+// Parameters:
+// - md: the MDFileInfo object representing the file or directory.
+// - opts: the options used to render the TOC.
+//
+// Returns:
+// - string: the rendered text.
+func EntryText(md MDFileInfo, opts TocOptions) string {
+	if !md.IsDir {
+		entry := FormatLink(md, opts) + EntryDetails(md, opts)
+		if opts.EditBaseURL != "" {
+			entry += fmt.Sprintf(" [%s](%s)", EditLinkText, EditURL(md, opts.EditBaseURL))
+		}
+		return entry
+	}
+	if md.LinkPath != "" {
+		return FormatLink(MDFileInfo{Title: md.Title, Path: md.LinkPath}, opts)
+	}
+	if opts.Format == FormatGitHubComment && !opts.AnchorMode {
+		// The directories link to their tree, which GitHub renders with their README
+		return FormatLink(md, opts)
+	}
+	return md.Title
+}
+
+// EditLinkText is the text of the edit links appended to the file entries with `-edit-base-url`.
+const EditLinkText = "✏️ edit"
+
+// EditURL returns the URL of the page editing a file: its escaped path relative to the root
+// directory joined to the base URL, e.g. `https://github.com/{owner}/{repo}/edit/main/docs/` and
+// `guides/start.md`.
+//
+// Parameters:
+// - md: the MDFileInfo object representing the file.
+// - baseURL: the URL of the root directory in the editor.
+//
+// Returns:
+// - string: the edit URL.
+func EditURL(md MDFileInfo, baseURL string) string {
+	if !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+	return baseURL + (&url.URL{Path: RelPath(md)}).EscapedPath()
+}
+
+// EntryDetails renders the ` (...)` suffix of a file entry with its details: its date with
+// opts.ShowDates, its age with opts.ShowAge and its number of headings with opts.ShowHeadingCount,
+// e.g. ` (2024-01-02, 3 days ago, 4 headings)`. The dates are left out when the modification time
+// of the file is unknown, e.g. for a remote file. It is an empty string if no detail is shown.
+//
+// Parameters:
+// - md: the MDFileInfo object representing the file.
+// - opts: the options used to render the TOC.
+//
+// Returns:
+// - string: the rendered suffix.
+func EntryDetails(md MDFileInfo, opts TocOptions) string {
+	if md.IsDir {
+		return ""
+	}
+	var parts []string
+	if opts.ShowDates && !md.ModTime.IsZero() {
+		parts = append(parts, md.ModTime.Format(opts.DateFormat))
+	}
+	if opts.ShowAge && !md.ModTime.IsZero() {
+		now := opts.Now
+		if now.IsZero() {
+			now = time.Now()
+		}
+		parts = append(parts, HumanizeAge(now.Sub(md.ModTime)))
+	}
+	if opts.ShowHeadingCount {
+		if md.HeadingCount == 1 {
+			parts = append(parts, "1 heading")
 		} else {
-			toc = fmt.Sprintf("%s- [%s](%s)\n", strings.Repeat(indent, md.Level-2), md.Title, md.Path)
+			parts = append(parts, strconv.Itoa(md.HeadingCount)+" headings")
 		}
 	}
-	keys := reflect.ValueOf(md.Children).MapKeys()
-	stringKeys := make([]string, len(keys))
-	for i, key := range keys {
-		stringKeys[i] = key.String()
+	if len(parts) == 0 {
+		return ""
 	}
-	if sortAsc {
-		sort.Strings(stringKeys)
-	} else {
-		sort.Sort(sort.Reverse(sort.StringSlice(stringKeys)))
+	return " (" + strings.Join(parts, ", ") + ")"
+}
+
+// HumanizeAge renders a duration as a relative age in the largest whole unit, e.g. `3 days ago`.
+// The months and years are 30 and 365 days long, the durations under a minute are `just now`.
+//
+// Parameters:
+// - age: the time elapsed since the event.
+//
+// Returns:
+// - string: the relative age.
+func HumanizeAge(age time.Duration) string {
+	units := []struct {
+		name string
+		size time.Duration
+	}{
+		{"year", 365 * 24 * time.Hour},
+		{"month", 30 * 24 * time.Hour},
+		{"day", 24 * time.Hour},
+		{"hour", time.Hour},
+		{"minute", time.Minute},
+	}
+	for _, unit := range units {
+		if n := int(age / unit.size); n >= 1 {
+			if n == 1 {
+				return "1 " + unit.name + " ago"
+			}
+			return strconv.Itoa(n) + " " + unit.name + "s ago"
+		}
 	}
+	return "just now"
+}
 
-	for _, key := range stringKeys {
-		toc += CreateTocTree(md.Children[key], indent, sortAsc)
+// RootHeading renders the `# Title` heading of the Markdown TOC, followed by the `## TocHeading`
+// subheading when opts.TocHeading is set.
+//
+// Parameters:
+// - md: the MDFileInfo object representing the root directory.
+// - opts: the options used to render the TOC.
+//
+// Returns:
+// - string: the rendered heading.
+func RootHeading(md MDFileInfo, opts TocOptions) string {
+	heading := "# " + md.Title + "\n"
+	if opts.TocHeading != "" && opts.Compact {
+		heading += "## " + opts.TocHeading + "\n"
+	} else if opts.TocHeading != "" {
+		heading += "\n## " + opts.TocHeading + "\n"
 	}
-	return toc
+	return heading
+}
+
+// FormatLink renders the link to a Markdown file in the link style of the options.
+//
+// The markdown style produces `[Title](path)`. The wiki style produces `[[path/to/file|Title]]`
+// as used by Obsidian and Foam, where the target is the note path without its extension. Wikilinks
+// have no escapes, so the brackets and pipes of the target and the title are stripped, see
+// EscapeWikiText. With opts.AnchorMode, the links point to the anchor of the file instead, see
+// LinkTarget.
+//
+// Parameters:
+// - md: the MDFileInfo object representing the file.
+// - opts: the options used to render the TOC.
+//
+// Returns:
+// - string: the rendered link.
+func FormatLink(md MDFileInfo, opts TocOptions) string {
+	if opts.LinkStyle == LinkStyleWiki {
+		target := RelPath(md)
+		target = strings.TrimSuffix(target, filepath.Ext(target))
+		if opts.AnchorMode {
+			target = LinkTarget(md, opts)
+		}
+		return fmt.Sprintf("[[%s|%s]]", EscapeWikiText(target), EscapeWikiText(md.Title))
+	}
+	return fmt.Sprintf("[%s](%s)", EscapeLinkText(md.Title), LinkTarget(md, opts))
+}
+
+// LinkTarget returns the target of the link to a file: its escaped path, or with opts.AnchorMode,
+// the `#anchor` of the section the file becomes when all the files are combined in one document,
+// see DirAnchor for a directory. With the github-comment format, it is the absolute URL of the file
+// on GitHub, see GitHubURL.
+//
+// Parameters:
+// - md: the MDFileInfo object representing the file.
+// - opts: the options used to render the TOC.
+//
+// Returns:
+// - string: the target of the link.
+func LinkTarget(md MDFileInfo, opts TocOptions) string {
+	if opts.AnchorMode && md.IsDir {
+		return "#" + DirAnchor(md, opts)
+	}
+	if opts.AnchorMode {
+		return "#" + FileAnchor(md, opts)
+	}
+	if opts.Format == FormatGitHubComment {
+		return GitHubURL(md, opts.GitHub)
+	}
+	return CleanLinkPath(md.Path)
+}
+
+// CleanLinkPath returns the canonical form of an escaped link path, without empty, `.` or `..`
+// segments and with forward slashes, e.g. `./a/b.md` for `./a//./b.md`, escaped with EscapePath.
+// The leading `./` of a relative path is kept.
+//
+// Parameters:
+// - escapedPath: the escaped path, as in MDFileInfo.Path.
+//
+// Returns:
+// - string: the escaped canonical path.
+func CleanLinkPath(escapedPath string) string {
+	unescaped, err := url.PathUnescape(escapedPath)
+	if err != nil {
+		return escapedPath
+	}
+	slashPath := filepath.ToSlash(unescaped)
+	cleaned := path.Clean(slashPath)
+	if strings.HasPrefix(slashPath, "./") && cleaned != "." && !strings.HasPrefix(cleaned, "../") && !path.IsAbs(cleaned) {
+		cleaned = "./" + cleaned
+	}
+	if cleaned == unescaped {
+		return escapedPath
+	}
+	return EscapePath(cleaned)
+}
+
+// EscapePath returns the escaped form of a relative path stored in MDFileInfo.Path, with forward
+// slashes whatever the OS, so that the links work on every platform. Each segment is escaped on its
+// own and the slashes are kept, e.g. `./a/my%20notes.md` for `.\a\my notes.md` on Windows.
+//
+// Parameters:
+// - relPath: the path, with the separators of the OS.
+//
+// Returns:
+// - string: the escaped slash-separated path.
+func EscapePath(relPath string) string {
+	return (&url.URL{Path: filepath.ToSlash(relPath)}).EscapedPath()
+}
+
+// FileAnchor returns the anchor of a file derived from its relative path without the extension,
+// e.g. `file-guides-advanced-scaling` for `guides/advanced/scaling.md`. The anchor of a directory is
+// derived from its whole relative path. The anchors are prefixed with FileAnchorPrefix, so that
+// they do not clash with the anchors GitHub derives from the headings of the TOC, e.g. `#intro`
+// for `## Intro`.
+//
+// Parameters:
+// - md: the MDFileInfo object representing the file or directory.
+// - opts: the options used to render the TOC, for the slug style.
+//
+// Returns:
+// - string: the anchor, without the `#`.
+func FileAnchor(md MDFileInfo, opts TocOptions) string {
+	path := RelPath(md)
+	if !md.IsDir {
+		path = strings.TrimSuffix(path, filepath.Ext(path))
+	}
+	return FileAnchorPrefix + Slugify(strings.ReplaceAll(path, "/", " "), opts.SlugStyle)
+}
+
+// FileAnchorPrefix is the prefix of the anchors returned by FileAnchor.
+const FileAnchorPrefix = "file-"
+
+// DirAnchor returns the anchor of the section of a directory: the slug of its title, or with
+// opts.NamespaceAnchors the slug of its path, see FileAnchor, so that the directories with the
+// same title in different places do not share an anchor.
+//
+// Parameters:
+// - md: the MDFileInfo object representing the directory.
+// - opts: the options used to render the TOC.
+//
+// Returns:
+// - string: the anchor, without the `#`.
+func DirAnchor(md MDFileInfo, opts TocOptions) string {
+	if opts.NamespaceAnchors {
+		return FileAnchor(md, opts)
+	}
+	return Slugify(md.Title, opts.SlugStyle)
+}
+
+// linkTextReplacer escapes the characters which end or break the text of a Markdown link.
+var linkTextReplacer = strings.NewReplacer(`\`, `\\`, `[`, `\[`, `]`, `\]`)
+
+// EscapeLinkText escapes the backslashes and square brackets of the text of a Markdown link,
+// so that a title such as `Arrays [a, b]` does not end the link text early.
+//
+// Parameters:
+// - text: the text of the link.
+//
+// Returns:
+// - string: the escaped text.
+func EscapeLinkText(text string) string {
+	return linkTextReplacer.Replace(text)
+}
+
+// wikiTextReplacer strips the characters which end or split a wikilink.
+var wikiTextReplacer = strings.NewReplacer("[", "", "]", "", "|", "-")
+
+// EscapeWikiText returns the target or the title of a wikilink without the square brackets, which
+// would end the link early, and with its pipes, which separate the target from the title, replaced
+// by hyphens, e.g. `A - B` for `A | B`.
+//
+// Parameters:
+// - text: the target or the title.
+//
+// Returns:
+// - string: the text safe to use in a wikilink.
+func EscapeWikiText(text string) string {
+	return wikiTextReplacer.Replace(text)
 }
@@ -0,0 +1,1518 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeTree creates the files of a test tree, keyed by their slash-separated paths, in a temporary
+// directory and returns its path.
+func writeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// testTocOptions returns the options the Markdown TOC is rendered with by default.
+func testTocOptions() TocOptions {
+	return TocOptions{
+		Indent:       "  ",
+		SortAsc:      true,
+		Sort:         SortName,
+		LinkStyle:    LinkStyleMarkdown,
+		Format:       FormatMarkdown,
+		SlugStyle:    SlugStyleGitHub,
+		HeadingDepth: FormatHeadingDepths[FormatMarkdown],
+	}
+}
+
+// testListOptions returns the options the files are listed with by default.
+func testListOptions() ListOptions {
+	strategy, _ := ParseTitleStrategy("", false, MultipleH1First)
+	return ListOptions{TitleStrategy: strategy}
+}
+
+// listTree lists the files of dir, failing the test on errors.
+func listTree(t *testing.T, dir string, opts ListOptions) MDFileInfo {
+	t.Helper()
+	md, err := ListMDFiles(dir, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return md
+}
+
+func TestSortedChildKeys(t *testing.T) {
+	children := map[string]MDFileInfo{"b.md": {Name: "b.md"}, "a": {Name: "a"}, "c.md": {Name: "c.md"}, "B.md": {Name: "B.md"}}
+	tests := []struct {
+		sortAsc bool
+		want    []string
+	}{
+		{true, []string{"B.md", "a", "b.md", "c.md"}},
+		{false, []string{"c.md", "b.md", "a", "B.md"}},
+	}
+	for _, tt := range tests {
+		for i := 0; i < 10; i++ {
+			if got := SortedChildKeys(MDFileInfo{Children: children}, TocOptions{SortAsc: tt.sortAsc}); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("SortedChildKeys(asc=%v) = %q, want %q", tt.sortAsc, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestCreateTocTreeDeterministic(t *testing.T) {
+	files := make(map[string]string)
+	for _, dir := range []string{"b", "a", "c/z", "c/y", "d"} {
+		for _, name := range []string{"3.md", "1.md", "2.md", "README.md"} {
+			files[dir+"/"+name] = "# " + dir + " " + name + "\n"
+		}
+	}
+	files["top.md"] = "# Top\n"
+	dir := writeTree(t, files)
+
+	for _, sortAsc := range []bool{true, false} {
+		opts := testTocOptions()
+		opts.SortAsc = sortAsc
+		want := CreateTocTree(listTree(t, dir, testListOptions()), opts)
+		for i := 0; i < 20; i++ {
+			if got := CreateTocTree(listTree(t, dir, testListOptions()), opts); got != want {
+				t.Fatalf("run %d, asc %v, differs:\n%s\nwant\n%s", i, sortAsc, got, want)
+			}
+		}
+	}
+}
+
+// listedPaths returns the relative paths of the files of the tree, in rendering order.
+func listedPaths(md MDFileInfo) []string {
+	var paths []string
+	for _, key := range SortedChildKeys(md, testTocOptions()) {
+		child := md.Children[key]
+		if child.IsDir {
+			paths = append(paths, listedPaths(child)...)
+			continue
+		}
+		relPath, _ := url.PathUnescape(child.Path)
+		paths = append(paths, strings.TrimPrefix(filepath.ToSlash(relPath), "./"))
+	}
+	return paths
+}
+
+func TestMatchesAnyPattern(t *testing.T) {
+	tests := []struct {
+		relPath  string
+		isDir    bool
+		patterns []string
+		want     bool
+	}{
+		{"drafts/a.md", false, []string{"drafts/*"}, true},
+		{"notes.md", false, []string{"*.md"}, true},
+		{"a/b/notes.md", false, []string{"notes.md"}, true},
+		{"a/b/notes.md", false, []string{"/notes.md"}, false},
+		{"build", true, []string{"build/"}, true},
+		{"build", false, []string{"build/"}, false},
+		{"a/b.md", false, []string{"b/*"}, false},
+		{".", true, []string{"*"}, false},
+		{"a.md", false, nil, false},
+	}
+	for _, tt := range tests {
+		if got := MatchesAnyPattern(tt.relPath, tt.isDir, tt.patterns); got != tt.want {
+			t.Errorf("MatchesAnyPattern(%q, %v, %q) = %v, want %v", tt.relPath, tt.isDir, tt.patterns, got, tt.want)
+		}
+	}
+}
+
+func TestListMDFilesIgnoreFile(t *testing.T) {
+	tests := []struct {
+		name   string
+		ignore string
+		want   []string
+	}{
+		{"none", "", []string{"build/out.md", "drafts/wip.md", "guides/notes.md", "guides/start.md", "intro.md"}},
+		{"comments and blanks", "# comment\n\n", []string{"build/out.md", "drafts/wip.md", "guides/notes.md", "guides/start.md", "intro.md"}},
+		{"directory", "build/\n", []string{"drafts/wip.md", "guides/notes.md", "guides/start.md", "intro.md"}},
+		{"glob", "drafts/*\n", []string{"build/out.md", "guides/notes.md", "guides/start.md", "intro.md"}},
+		{"base name", "notes.md\n", []string{"build/out.md", "drafts/wip.md", "guides/start.md", "intro.md"}},
+		{"several", "build/\n  drafts/*  \nintro.md\n", []string{"guides/notes.md", "guides/start.md"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeTree(t, map[string]string{
+				"intro.md":        "# Intro\n",
+				"guides/start.md": "# Start\n",
+				"guides/notes.md": "# Notes\n",
+				"drafts/wip.md":   "# WIP\n",
+				"build/out.md":    "# Out\n",
+				IgnoreFileName:    tt.ignore,
+			})
+			if got := listedPaths(listTree(t, dir, testListOptions())); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("listed %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComposeOutput(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"intro.md":  "# Handbook\n\nWelcome.\n\n",
+		"footer.md": "Maintained by the docs team.\n",
+	})
+	intro := filepath.Join(dir, "intro.md")
+	footer := filepath.Join(dir, "footer.md")
+	toc := "- [Start](./start.md)\n"
+	tests := []struct {
+		name    string
+		prepend string
+		append  string
+		want    string
+	}{
+		{"toc only", "", "", toc},
+		{"prepend", intro, "", "# Handbook\n\nWelcome.\n\n- [Start](./start.md)\n"},
+		{"append", "", footer, "- [Start](./start.md)\n\nMaintained by the docs team.\n"},
+		{"both", intro, footer, "# Handbook\n\nWelcome.\n\n- [Start](./start.md)\n\nMaintained by the docs team.\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ComposeOutput(tt.prepend, toc, tt.append)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("ComposeOutput() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if _, err := ComposeOutput(filepath.Join(dir, "missing.md"), toc, ""); err == nil {
+		t.Error("ComposeOutput() with a missing file = nil error, want an error")
+	}
+}
+
+func TestFormatLink(t *testing.T) {
+	tests := []struct {
+		name string
+		md   MDFileInfo
+		opts TocOptions
+		want string
+	}{
+		{"markdown", MDFileInfo{Title: "Start", Path: "./guides/start.md"}, TocOptions{}, "[Start](./guides/start.md)"},
+		{"markdown brackets", MDFileInfo{Title: "Arrays [a, b]", Path: "./a.md"}, TocOptions{}, `[Arrays \[a, b\]](./a.md)`},
+		{"wiki", MDFileInfo{Title: "Start", Path: "./guides/start.md"}, TocOptions{LinkStyle: LinkStyleWiki}, "[[guides/start|Start]]"},
+		{"wiki closing brackets", MDFileInfo{Title: "Arrays [[a]]", Path: "./a.md"}, TocOptions{LinkStyle: LinkStyleWiki}, "[[a|Arrays a]]"},
+		{"wiki pipe", MDFileInfo{Title: "A | B", Path: "./a.md"}, TocOptions{LinkStyle: LinkStyleWiki}, "[[a|A - B]]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatLink(tt.md, tt.opts); got != tt.want {
+				t.Errorf("FormatLink() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateTocTreeGitHubNesting(t *testing.T) {
+	md := MDFileInfo{
+		Title: "Docs",
+		IsDir: true,
+		Path:  ".",
+		Children: map[string]MDFileInfo{
+			"guides": {
+				Name: "guides", Title: "guides", IsDir: true, Level: 1, Path: "./guides",
+				Children: map[string]MDFileInfo{
+					"start.md": {Name: "start.md", Title: "Start", Level: 2, Path: "./guides/start.md"},
+					"advanced": {
+						Name: "advanced", Title: "advanced", IsDir: true, Level: 2, Path: "./guides/advanced",
+						Children: map[string]MDFileInfo{
+							"scaling.md": {Name: "scaling.md", Title: "Scaling", Level: 3, Path: "./guides/advanced/scaling.md"},
+							"tuning": {
+								Name: "tuning", Title: "tuning", IsDir: true, Level: 3, Path: "./guides/advanced/tuning",
+								Children: map[string]MDFileInfo{
+									"cache.md": {Name: "cache.md", Title: "Cache", Level: 4, Path: "./guides/advanced/tuning/cache.md"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	// Every nested item starts at the content column of its parent, after "- ", which GitHub
+	// and CommonMark require to render it as a sublist
+	want := "# Docs\n\n## guides\n\n" +
+		"- advanced\n" +
+		"  - [Scaling](./guides/advanced/scaling.md)\n" +
+		"  - tuning\n" +
+		"    - [Cache](./guides/advanced/tuning/cache.md)\n" +
+		"- [Start](./guides/start.md)\n"
+	opts := TocOptions{Indent: "  ", SortAsc: true, HeadingDepth: FormatHeadingDepths[FormatMarkdown]}
+	if got := CreateTocTree(md, opts); got != want {
+		t.Errorf("CreateTocTree() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+// sampleDocs lists a small documentation tree titled Docs:
+//
+//	intro.md                   # Intro
+//	guides/start.md            # Getting Started
+//	guides/advanced/scaling.md # Scaling
+func sampleDocs(t *testing.T) MDFileInfo {
+	t.Helper()
+	dir := writeTree(t, map[string]string{
+		"intro.md":                   "# Intro\n",
+		"guides/start.md":            "# Getting Started\n",
+		"guides/advanced/scaling.md": "# Scaling\n",
+	})
+	md := listTree(t, dir, testListOptions())
+	md.Title = "Docs"
+	return md
+}
+
+func TestDirTitleOverride(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{"none", map[string]string{"guides/a.md": "# A\n"}, "guides"},
+		{".title", map[string]string{"guides/a.md": "# A\n", "guides/.title": "\n  User Guides  \nignored\n"}, "User Guides"},
+		{"_title", map[string]string{"guides/a.md": "# A\n", "guides/_title": "Guides (underscore)\n"}, "Guides (underscore)"},
+		{".title first", map[string]string{"guides/a.md": "# A\n", "guides/.title": "Dot\n", "guides/_title": "Underscore\n"}, "Dot"},
+		{"blank", map[string]string{"guides/a.md": "# A\n", "guides/.title": "\n\n"}, "guides"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			md := listTree(t, writeTree(t, tt.files), testListOptions())
+			if got := md.Children["guides"].Title; got != tt.want {
+				t.Errorf("title = %q, want %q", got, tt.want)
+			}
+			if _, ok := md.Children["guides"].Children[".title"]; ok {
+				t.Error("the title file is listed")
+			}
+		})
+	}
+}
+
+// deepTree returns a tree of depth nested directories, each holding an index.md file.
+func deepTree(depth int) MDFileInfo {
+	md := MDFileInfo{Name: "d" + strconv.Itoa(depth), Title: "level " + strconv.Itoa(depth), IsDir: true, Level: depth,
+		Children: map[string]MDFileInfo{"index.md": {Name: "index.md", Title: "leaf", Level: depth + 1, Path: "./leaf.md"}}}
+	for level := depth - 1; level >= 0; level-- {
+		md = MDFileInfo{Name: "d" + strconv.Itoa(level), Title: "level " + strconv.Itoa(level), IsDir: true, Level: level,
+			Children: map[string]MDFileInfo{md.Name: md}, Path: "."}
+	}
+	return md
+}
+
+func TestCreateTocTreeDeep(t *testing.T) {
+	const depth = 5000
+	md := deepTree(depth)
+	toc := CreateTocTree(md, testTocOptions())
+	items := 0
+	for _, line := range strings.Split(toc, "\n") {
+		if strings.HasPrefix(strings.TrimLeft(line, " "), "- ") {
+			items++
+		}
+	}
+	// The directories below the top-level sections and the file are list items
+	if items != depth {
+		t.Errorf("the TOC of a %d-level tree has %d list items, want %d", depth, items, depth)
+	}
+	last := strings.Repeat("  ", depth-1) + "- [leaf](./leaf.md)\n"
+	if !strings.HasSuffix(toc, last) {
+		t.Errorf("the TOC does not end with the deepest file indented %d times", depth-1)
+	}
+
+	tests := []struct {
+		maxDepth int
+		want     int
+	}{
+		{1, 1},
+		{3, 3},
+		{depth, depth},
+	}
+	for _, tt := range tests {
+		limited := LimitDepth(md, tt.maxDepth)
+		if got := SubtreeDepth(limited); got != tt.want {
+			t.Errorf("LimitDepth(%d) has depth %d, want %d", tt.maxDepth, got, tt.want)
+		}
+	}
+}
+
+func TestTocHeading(t *testing.T) {
+	tests := []struct {
+		name    string
+		heading string
+		compact bool
+		format  string
+		want    string
+	}{
+		{"none", "", false, FormatMarkdown, "# Docs\n\n## guides\n"},
+		{"markdown", "Contents", false, FormatMarkdown, "# Docs\n\n## Contents\n\n## guides\n"},
+		{"compact", "Contents", true, FormatMarkdown, "# Docs\n## Contents\n## guides\n"},
+		{"html", "Contents", false, FormatHTML, "<h1>Docs</h1>\n<h2>Contents</h2>\n<ul>\n"},
+		{"html escaped", "Q&A", false, FormatHTML, "<h1>Docs</h1>\n<h2>Q&amp;A</h2>\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := testTocOptions()
+			opts.TocHeading = tt.heading
+			opts.Compact = tt.compact
+			opts.Format = tt.format
+			if got := RenderToc(sampleDocs(t), opts); !strings.HasPrefix(got, tt.want) {
+				t.Errorf("RenderToc() =\n%s\nwant a prefix\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListMDFilesRelativePaths(t *testing.T) {
+	// The name of the root reappears in the tree, a plain string replacement mangles these paths
+	root := writeTree(t, map[string]string{
+		"docs/docs/intro.md":   "# Intro\n",
+		"docs/guides/docs.md":  "# Docs guide\n",
+		"docs/guides/start.md": "# Start\n",
+	})
+	dir := filepath.Join(root, "docs")
+	want := []string{"docs/intro.md", "guides/docs.md", "guides/start.md"}
+	tests := []struct {
+		name string
+		dir  string
+	}{
+		{"clean", dir},
+		{"trailing separator", dir + string(filepath.Separator)},
+		{"dot segment", filepath.Join(root, ".") + string(filepath.Separator) + "." + string(filepath.Separator) + "docs"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			md := listTree(t, tt.dir, testListOptions())
+			if got := listedPaths(md); !reflect.DeepEqual(got, want) {
+				t.Errorf("listed %q, want %q", got, want)
+			}
+			if got, want := md.Children["guides"].Children["docs.md"].Path, "./guides/docs.md"; got != want {
+				t.Errorf("path = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestReadmeAsSection(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"guides/README.md":     "# User Guides\n",
+		"guides/start.md":      "# Getting Started\n",
+		"reference/api.md":     "# API\n",
+		"reference/.title":     "Reference\n",
+		"reference/README.txt": "not markdown\n",
+	})
+	tests := []struct {
+		name            string
+		readmeAsSection bool
+		want            string
+	}{
+		{"off", false, "# Docs\n\n## guides\n\n- [Getting Started](./guides/start.md)\n\n## Reference\n\n- [API](./reference/api.md)\n"},
+		{"on", true, "# Docs\n\n## [User Guides](./guides/README.md)\n\n- [Getting Started](./guides/start.md)\n\n## Reference\n\n- [API](./reference/api.md)\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := testListOptions()
+			opts.ReadmeAsSection = tt.readmeAsSection
+			md := listTree(t, dir, opts)
+			md.Title = "Docs"
+			if got := CreateTocTree(md, testTocOptions()); got != tt.want {
+				t.Errorf("CreateTocTree() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+// runMain runs the command with the given arguments and returns what it printed to stdout.
+// The arguments must be valid, the command exits on errors.
+func runMain(t *testing.T, args ...string) string {
+	t.Helper()
+	oldArgs, oldFlags, oldStdout := os.Args, flag.CommandLine, os.Stdout
+	defer func() {
+		os.Args, flag.CommandLine, os.Stdout = oldArgs, oldFlags, oldStdout
+	}()
+	os.Args = append([]string{"mdtocgen"}, args...)
+	flag.CommandLine = flag.NewFlagSet("mdtocgen", flag.ExitOnError)
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = w
+	out := make(chan []byte)
+	go func() {
+		content, _ := io.ReadAll(r)
+		out <- content
+	}()
+	main()
+	w.Close()
+	return string(<-out)
+}
+
+func TestExpandEnvPaths(t *testing.T) {
+	dir := writeTree(t, map[string]string{"intro.md": "# Intro\n"})
+	outDir := t.TempDir()
+	t.Setenv("MDTOCGEN_TEST_DOCS", dir)
+	t.Setenv("MDTOCGEN_TEST_OUT", outDir)
+	tests := []struct {
+		name string
+		dir  string
+		out  string
+	}{
+		{"plain", "$MDTOCGEN_TEST_DOCS", "$MDTOCGEN_TEST_OUT/plain.md"},
+		{"braces", "${MDTOCGEN_TEST_DOCS}", "${MDTOCGEN_TEST_OUT}/braces.md"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runMain(t, "-dir", tt.dir, "-out", tt.out, "-t", "Docs")
+			content, err := os.ReadFile(os.ExpandEnv(tt.out))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := "# Docs\n\n## [Intro](./intro.md)\n"; string(content) != want {
+				t.Errorf("output = %q, want %q", content, want)
+			}
+		})
+	}
+}
+
+func TestListMDFilesIncludeExclude(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"intro.md":              "# Intro\n",
+		"guides/start.md":       "# Start\n",
+		"guides/internal.md":    "# Internal\n",
+		"api/v1/users.md":       "# Users\n",
+		"api/v2/users.md":       "# Users v2\n",
+		"api/v2/internal/db.md": "# DB\n",
+	})
+	tests := []struct {
+		name    string
+		include []string
+		exclude []string
+		want    []string
+	}{
+		{"all", nil, nil, []string{"api/v1/users.md", "api/v2/internal/db.md", "api/v2/users.md", "guides/internal.md", "guides/start.md", "intro.md"}},
+		{"include glob", []string{"api/*/*.md"}, nil, []string{"api/v1/users.md", "api/v2/users.md"}},
+		{"include base name", []string{"users.md"}, nil, []string{"api/v1/users.md", "api/v2/users.md"}},
+		{"include several", []string{"guides/*", "intro.md"}, nil, []string{"guides/internal.md", "guides/start.md", "intro.md"}},
+		{"exclude directory", nil, []string{"api/"}, []string{"guides/internal.md", "guides/start.md", "intro.md"}},
+		{"include and exclude", []string{"guides/*", "api/v2/*.md"}, []string{"internal.md"}, []string{"api/v2/users.md", "guides/start.md"}},
+		{"exclude wins", []string{"intro.md"}, []string{"intro.md"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := testListOptions()
+			opts.Include = tt.include
+			opts.Exclude = tt.exclude
+			got := listedPaths(listTree(t, dir, opts))
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("listed %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListMDFilesDrafts(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"done.md":    "# Done\n",
+		"wip.md":     "---\ndraft: true\n---\n# WIP\n",
+		"private.md": "---\npublished: false\n---\n# Private\n",
+	})
+	tests := []struct {
+		mode string
+		want []string
+	}{
+		{"", []string{"Done", "Private", "WIP"}},
+		{DraftsExclude, []string{"Done"}},
+		{DraftsAnnotate, []string{"Done", "Private (draft)", "WIP (draft)"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			opts := testListOptions()
+			opts.MarkDrafts = tt.mode
+			var got []string
+			for _, entry := range FlattenFiles(listTree(t, dir, opts), testTocOptions()) {
+				got = append(got, entry.File.Title)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("titles = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTaskList(t *testing.T) {
+	tests := []struct {
+		frontmatter map[string]string
+		want        string
+	}{
+		{nil, "[ ] "},
+		{map[string]string{"reviewed": "true"}, "[x] "},
+		{map[string]string{"reviewed": "false"}, "[ ] "},
+		{map[string]string{"reviewed": "maybe"}, "[ ] "},
+	}
+	for _, tt := range tests {
+		if got := TaskBox(MDFileInfo{Frontmatter: tt.frontmatter}); got != tt.want {
+			t.Errorf("TaskBox(%v) = %q, want %q", tt.frontmatter, got, tt.want)
+		}
+	}
+
+	dir := writeTree(t, map[string]string{
+		"guides/start.md": "---\nreviewed: true\n---\n# Start\n",
+		"guides/todo.md":  "# Todo\n",
+	})
+	md := listTree(t, dir, testListOptions())
+	md.Title = "Docs"
+	opts := testTocOptions()
+	opts.TaskList = true
+	want := "# Docs\n\n## guides\n\n- [x] [Start](./guides/start.md)\n- [ ] [Todo](./guides/todo.md)\n"
+	if got := CreateTocTree(md, opts); got != want {
+		t.Errorf("CreateTocTree() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+// wideTree returns the files of a tree of dirs directories holding files files each.
+func wideTree(dirs, files int) map[string]string {
+	tree := make(map[string]string)
+	for d := 0; d < dirs; d++ {
+		for f := 0; f < files; f++ {
+			name := "section" + strconv.Itoa(d) + "/sub" + strconv.Itoa(f%3) + "/page" + strconv.Itoa(f) + ".md"
+			tree[name] = "# Page " + strconv.Itoa(d) + "." + strconv.Itoa(f) + "\n\nSome text.\n"
+		}
+	}
+	return tree
+}
+
+func TestListMDFilesParallel(t *testing.T) {
+	tree := wideTree(8, 12)
+	tree["README.md"] = "# Root\n"
+	tree[".mdtocignore"] = "section7/\n"
+	tree["section1/.title"] = "First section\n"
+	dir := writeTree(t, tree)
+	serial := RenderToc(listTree(t, dir, testListOptions()), testTocOptions())
+	for _, parallel := range []int{2, 4, 16} {
+		opts := testListOptions()
+		opts.Parallel = parallel
+		for i := 0; i < 5; i++ {
+			if got := RenderToc(listTree(t, dir, opts), testTocOptions()); got != serial {
+				t.Fatalf("the TOC listed with %d workers differs from the serial one:\n%s\nwant\n%s", parallel, got, serial)
+			}
+		}
+	}
+	if strings.Contains(serial, "section7") || !strings.Contains(serial, "## First section") {
+		t.Errorf("unexpected TOC:\n%s", serial)
+	}
+}
+
+func BenchmarkListMDFiles(b *testing.B) {
+	dir := b.TempDir()
+	for name, content := range wideTree(20, 50) {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			b.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			b.Fatal(err)
+		}
+	}
+	for _, parallel := range []int{1, 8} {
+		b.Run("parallel="+strconv.Itoa(parallel), func(b *testing.B) {
+			opts := testListOptions()
+			opts.Parallel = parallel
+			for i := 0; i < b.N; i++ {
+				if _, err := ListMDFiles(dir, opts); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestCollapseThreshold(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold int
+		collapsed bool
+	}{
+		{"disabled", 0, false},
+		{"below", 2, true},
+		{"equal", 3, false},
+		{"above", 10, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := testTocOptions()
+			opts.CollapseThreshold = tt.threshold
+			got := CreateTocTree(sampleDocs(t), opts)
+			want := "## guides\n\n<details>\n<summary>Show 3 entries</summary>\n\n- advanced\n"
+			if strings.Contains(got, want) != tt.collapsed {
+				t.Errorf("CreateTocTree() collapsed = %v, want %v:\n%s", !tt.collapsed, tt.collapsed, got)
+			}
+			if strings.Count(got, "<details>") != strings.Count(got, "</details>") {
+				t.Errorf("CreateTocTree() has unbalanced <details>:\n%s", got)
+			}
+			if strings.Contains(got, "<summary>Show 0") || strings.Contains(got, "## [Intro](./intro.md)\n\n<details>") {
+				t.Errorf("CreateTocTree() collapsed a small section:\n%s", got)
+			}
+		})
+	}
+}
+
+func TestEscapeLinkText(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Plain", "Plain"},
+		{"Arrays [a, b]", `Arrays \[a, b\]`},
+		{"Math $x_1 + [y]$", `Math $x_1 + \[y\]$`},
+		{`Path C:\docs`, `Path C:\\docs`},
+		{`\[`, `\\\[`},
+	}
+	for _, tt := range tests {
+		if got := EscapeLinkText(tt.text); got != tt.want {
+			t.Errorf("EscapeLinkText(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+	dir := writeTree(t, map[string]string{"math.md": "# Sets $[0, 1]$\n"})
+	got := CreateTocTree(listTree(t, dir, testListOptions()), testTocOptions())
+	if want := `[Sets $\[0, 1\]$](./math.md)`; !strings.Contains(got, want) {
+		t.Errorf("CreateTocTree() does not contain %q:\n%s", want, got)
+	}
+}
+
+func TestFileAnchor(t *testing.T) {
+	tests := []struct {
+		name string
+		md   MDFileInfo
+		want string
+	}{
+		{"root file", MDFileInfo{Name: "intro.md", Path: "./intro.md"}, "file-intro"},
+		{"nested file", MDFileInfo{Name: "start.md", Path: "./guides/start.md"}, "file-guides-start"},
+		{"escaped path", MDFileInfo{Name: "My Notes.md", Path: "./guides/My%20Notes.md"}, "file-guides-my-notes"},
+		{"directory", MDFileInfo{Name: "advanced", IsDir: true, Path: "./guides/advanced"}, "file-guides-advanced"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := testTocOptions()
+			if got := FileAnchor(tt.md, opts); got != tt.want {
+				t.Errorf("FileAnchor() = %q, want %q", got, tt.want)
+			}
+			if tt.md.IsDir {
+				return
+			}
+			opts.AnchorMode = true
+			if got, want := LinkTarget(tt.md, opts), "#"+tt.want; got != want {
+				t.Errorf("LinkTarget() = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestShowDates(t *testing.T) {
+	dir := writeTree(t, map[string]string{"intro.md": "# Intro\n"})
+	modTime := time.Date(2024, 1, 2, 12, 0, 0, 0, time.Local)
+	if err := os.Chtimes(filepath.Join(dir, "intro.md"), modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	md := listTree(t, dir, testListOptions())
+	tests := []struct {
+		name   string
+		show   bool
+		layout string
+		want   string
+	}{
+		{"hidden", false, "2006-01-02", "## [Intro](./intro.md)\n"},
+		{"date", true, "2006-01-02", "## [Intro](./intro.md) (2024-01-02)\n"},
+		{"layout", true, "Jan 2, 2006", "## [Intro](./intro.md) (Jan 2, 2024)\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := testTocOptions()
+			opts.ShowDates = tt.show
+			opts.DateFormat = tt.layout
+			if got := CreateTocTree(md, opts); !strings.Contains(got, tt.want) {
+				t.Errorf("CreateTocTree() does not contain %q:\n%s", tt.want, got)
+			}
+		})
+	}
+	if got := EntryDetails(MDFileInfo{Name: "remote.md"}, TocOptions{ShowDates: true, DateFormat: "2006"}); got != "" {
+		t.Errorf("EntryDetails() of an unknown time = %q, want none", got)
+	}
+}
+
+func TestFenceToc(t *testing.T) {
+	tests := []struct {
+		name string
+		toc  string
+		want string
+	}{
+		{"plain", "# Docs\n\n- [A](a.md)\n\n", "```markdown\n# Docs\n\n- [A](a.md)\n```\n"},
+		{"no trailing newline", "# Docs", "```markdown\n# Docs\n```\n"},
+		{"backtick title", "# Docs\n- [Use ```go```](a.md)\n", "````markdown\n# Docs\n- [Use ```go```](a.md)\n````\n"},
+		{"longer run", "# ````x````\n", "`````markdown\n# ````x````\n`````\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FenceToc(tt.toc); got != tt.want {
+				t.Errorf("FenceToc() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListMDFilesSingleFile(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"intro.md":        "# Intro\n\n## Part\n",
+		"guides/start.md": "# Getting Started\n",
+	})
+	file := filepath.Join(dir, "intro.md")
+	tests := []struct {
+		name string
+		path string
+		root string
+		want []string
+	}{
+		{"directory", dir, dir, []string{"guides/start.md", "intro.md"}},
+		{"file", file, dir, []string{"intro.md"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ListRoot(tt.path); got != tt.root {
+				t.Errorf("ListRoot(%q) = %q, want %q", tt.path, got, tt.root)
+			}
+			if got := listedPaths(listTree(t, tt.path, testListOptions())); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ListMDFiles(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+	md := listTree(t, file, testListOptions())
+	if got, want := CreateTocTree(md, testTocOptions()), "# Intro\n\n## [Intro](./intro.md)\n\n"; got != want {
+		t.Errorf("CreateTocTree() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestCleanLinkPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"./a/b.md", "./a/b.md"},
+		{"./a//./b.md", "./a/b.md"},
+		{"./a/x/../b.md", "./a/b.md"},
+		{"./../b.md", "../b.md"},
+		{"../b.md", "../b.md"},
+		{"a//b.md", "a/b.md"},
+		{"./My%20Notes.md", "./My%20Notes.md"},
+		{"./a//My%20Notes.md", "./a/My%20Notes.md"},
+		{".%2Fa%2F%2Fb.md", "./a/b.md"},
+		{"%zz", "%zz"},
+	}
+	for _, tt := range tests {
+		if got := CleanLinkPath(tt.path); got != tt.want {
+			t.Errorf("CleanLinkPath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestTee(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"intro.md":        "# Intro\n",
+		"guides/start.md": "# Getting Started\n",
+	})
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"streamed", nil},
+		{"rendered", []string{"-fenced"}},
+		{"other format", []string{"-format", FormatText}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outFile := filepath.Join(t.TempDir(), "TOC.md")
+			args := append([]string{"-dir", dir, "-t", "Docs"}, tt.args...)
+			stdout := runMain(t, args...)
+			if out := runMain(t, append(args, "-out", outFile)...); out != "" {
+				t.Errorf("stdout without -tee = %q, want nothing", out)
+			}
+			teed := runMain(t, append(args, "-out", outFile, "-tee")...)
+			content, err := os.ReadFile(outFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if teed != string(content) {
+				t.Errorf("stdout =\n%s\nfile =\n%s", teed, content)
+			}
+			if teed != stdout {
+				t.Errorf("stdout with -tee =\n%s\nwithout -out =\n%s", teed, stdout)
+			}
+		})
+	}
+}
+
+func TestListMDFilesExcludeTitle(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"intro.md":         "# Intro\n",
+		"legacy.md":        "# Legacy API\n",
+		"guides/start.md":  "# Getting Started\n",
+		"guides/legacy.md": "# legacy notes\n",
+	})
+	tests := []struct {
+		name    string
+		pattern string
+		want    []string
+	}{
+		{"none", "", []string{"guides/legacy.md", "guides/start.md", "intro.md", "legacy.md"}},
+		{"prefix", "^Legacy", []string{"guides/legacy.md", "guides/start.md", "intro.md"}},
+		{"case-insensitive", "(?i)legacy", []string{"guides/start.md", "intro.md"}},
+		{"anywhere", "Start", []string{"guides/legacy.md", "intro.md", "legacy.md"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := testListOptions()
+			if tt.pattern != "" {
+				opts.ExcludeTitle = regexp.MustCompile(tt.pattern)
+			}
+			if got := listedPaths(listTree(t, dir, opts)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ListMDFiles() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// failingWriter fails every write.
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, os.ErrClosed
+}
+
+func TestStreamedToc(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"intro.md":                   "# Intro\n",
+		"guides/start.md":            "# Getting Started\n",
+		"guides/install.md":          "# Installation\n",
+		"guides/advanced/scaling.md": "# Scaling\n",
+	})
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"default", nil},
+		{"heading depth", []string{"-heading-depth", "2"}},
+		{"compact", []string{"-compact"}},
+		{"collapsed", []string{"-collapse-threshold", "1"}},
+		{"max entries", []string{"-max-entries-per-section", "1"}},
+		{"no trailing newline", []string{"-trailing-newline", NewlineNone}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outFile := filepath.Join(t.TempDir(), "TOC.md")
+			args := append([]string{"-dir", dir, "-t", "Docs"}, tt.args...)
+			built := runMain(t, args...)
+			runMain(t, append(args, "-out", outFile)...)
+			streamed, err := os.ReadFile(outFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(streamed) != built {
+				t.Errorf("streamed =\n%s\nbuilt =\n%s", streamed, built)
+			}
+		})
+	}
+	md := listTree(t, dir, testListOptions())
+	var sb strings.Builder
+	if err := WriteTocTree(&sb, md, testTocOptions()); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := sb.String(), CreateTocTree(md, testTocOptions()); got != want {
+		t.Errorf("WriteTocTree() =\n%s\nCreateTocTree() =\n%s", got, want)
+	}
+	if err := WriteTocTree(failingWriter{}, md, testTocOptions()); err == nil {
+		t.Error("WriteTocTree() to a failing writer returned no error")
+	}
+}
+
+func TestSelfExclude(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"intro.md":      "# Intro\n",
+		"TOC.md":        "# Docs\n",
+		"guides/TOC.md": "# Guides TOC\n",
+	})
+	tests := []struct {
+		name    string
+		outFile string
+		want    []string
+	}{
+		{"no output", "", []string{"TOC.md", "guides/TOC.md", "intro.md"}},
+		{"in the root", filepath.Join(dir, "TOC.md"), []string{"guides/TOC.md", "intro.md"}},
+		{"in a subdirectory", filepath.Join(dir, "guides", "TOC.md"), []string{"TOC.md", "intro.md"}},
+		{"relative path", filepath.Join(dir, "guides", "..", "TOC.md"), []string{"guides/TOC.md", "intro.md"}},
+		{"outside", filepath.Join(t.TempDir(), "TOC.md"), []string{"TOC.md", "guides/TOC.md", "intro.md"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := testListOptions()
+			opts.OutFile = tt.outFile
+			if got := listedPaths(listTree(t, dir, opts)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ListMDFiles() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	outFile := filepath.Join(dir, "TOC.md")
+	runMain(t, "-dir", dir, "-t", "Docs", "-out", outFile)
+	runMain(t, "-dir", dir, "-t", "Docs", "-out", outFile)
+	content, err := os.ReadFile(outFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(content), "(./TOC.md)") {
+		t.Errorf("the output lists itself:\n%s", content)
+	}
+}
+
+func TestHumanizeAge(t *testing.T) {
+	day := 24 * time.Hour
+	tests := []struct {
+		age  time.Duration
+		want string
+	}{
+		{0, "just now"},
+		{59 * time.Second, "just now"},
+		{time.Minute, "1 minute ago"},
+		{90 * time.Minute, "1 hour ago"},
+		{5 * time.Hour, "5 hours ago"},
+		{day, "1 day ago"},
+		{3 * day, "3 days ago"},
+		{45 * day, "1 month ago"},
+		{364 * day, "12 months ago"},
+		{800 * day, "2 years ago"},
+	}
+	for _, tt := range tests {
+		if got := HumanizeAge(tt.age); got != tt.want {
+			t.Errorf("HumanizeAge(%v) = %q, want %q", tt.age, got, tt.want)
+		}
+	}
+}
+
+func TestShowAge(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	md := MDFileInfo{Title: "Docs", IsDir: true, Children: map[string]MDFileInfo{
+		"new.md":    {Name: "new.md", Title: "New", Level: 1, Path: "./new.md", ModTime: now.Add(-2 * time.Hour)},
+		"old.md":    {Name: "old.md", Title: "Old", Level: 1, Path: "./old.md", ModTime: now.AddDate(0, 0, -3)},
+		"remote.md": {Name: "remote.md", Title: "Remote", Level: 1, Path: "./remote.md"},
+	}}
+	opts := testTocOptions()
+	opts.ShowAge = true
+	opts.Now = now
+	want := "# Docs\n\n" +
+		"## [New](./new.md) (2 hours ago)\n\n\n" +
+		"## [Old](./old.md) (3 days ago)\n\n\n" +
+		"## [Remote](./remote.md)\n\n"
+	if got := CreateTocTree(md, opts); got != want {
+		t.Errorf("CreateTocTree() =\n%s\nwant\n%s", got, want)
+	}
+	opts.ShowDates = true
+	opts.DateFormat = "2006-01-02"
+	if got, want := EntryDetails(md.Children["old.md"], opts), " (2024-03-07, 3 days ago)"; got != want {
+		t.Errorf("EntryDetails() = %q, want %q", got, want)
+	}
+}
+
+func TestHeadingDepth(t *testing.T) {
+	tests := []struct {
+		name  string
+		depth int
+		want  string
+	}{
+		{"list only", 0, "# Docs\n\n" +
+			"- guides\n" +
+			"  - advanced\n" +
+			"    - [Scaling](./guides/advanced/scaling.md)\n" +
+			"  - [Getting Started](./guides/start.md)\n" +
+			"- [Intro](./intro.md)\n"},
+		{"default", FormatHeadingDepths[FormatMarkdown], "# Docs\n\n" +
+			"## guides\n\n" +
+			"- advanced\n" +
+			"  - [Scaling](./guides/advanced/scaling.md)\n" +
+			"- [Getting Started](./guides/start.md)\n\n" +
+			"## [Intro](./intro.md)\n\n"},
+		{"two levels", 2, "# Docs\n\n" +
+			"## guides\n\n\n" +
+			"### advanced\n\n" +
+			"- [Scaling](./guides/advanced/scaling.md)\n\n" +
+			"### [Getting Started](./guides/start.md)\n\n\n" +
+			"## [Intro](./intro.md)\n\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := testTocOptions()
+			opts.HeadingDepth = tt.depth
+			if got := CreateTocTree(sampleDocs(t), opts); got != tt.want {
+				t.Errorf("CreateTocTree() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+	for _, level := range []int{1, 6, 7, 10} {
+		want := strings.Repeat("#", level)
+		if level > 6 {
+			want = "######"
+		}
+		if got := HeadingMarker(level); got != want {
+			t.Errorf("HeadingMarker(%d) = %q, want %q", level, got, want)
+		}
+	}
+}
+
+func TestIndexFileFirst(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"guides/index.md": "# Overview\n",
+		"guides/about.md": "# About\n",
+		"guides/zeta.md":  "# Zeta\n",
+	})
+	md := listTree(t, dir, testListOptions())
+	tests := []struct {
+		name string
+		opts func(opts *TocOptions)
+		want []string
+	}{
+		{"ascending", func(opts *TocOptions) {}, []string{"guides/index.md", "guides/about.md", "guides/zeta.md"}},
+		{"descending", func(opts *TocOptions) { opts.SortAsc = false }, []string{"guides/index.md", "guides/zeta.md", "guides/about.md"}},
+		{"weight", func(opts *TocOptions) { opts.Sort = SortWeight }, []string{"guides/index.md", "guides/about.md", "guides/zeta.md"}},
+		{"order file", func(opts *TocOptions) { opts.Order = map[string]int{"guides/zeta": 0} }, []string{"guides/index.md", "guides/zeta.md", "guides/about.md"}},
+		{"listing order", func(opts *TocOptions) { opts.Sort = SortNone; opts.SortAsc = false }, []string{"guides/index.md", "guides/about.md", "guides/zeta.md"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := testTocOptions()
+			tt.opts(&opts)
+			var got []string
+			for _, entry := range FlattenFiles(md, opts) {
+				got = append(got, RelPath(entry.File))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FlattenFiles() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// The directory links to its README.md, the index.md next to it is sorted as usual
+	md = listTree(t, writeTree(t, map[string]string{
+		"guides/README.md": "# Guides\n",
+		"guides/index.md":  "# Overview\n",
+		"guides/about.md":  "# About\n",
+	}), testListOptions())
+	var got []string
+	for _, entry := range FlattenFiles(md, testTocOptions()) {
+		got = append(got, RelPath(entry.File))
+	}
+	if want := []string{"guides/about.md", "guides/index.md"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FlattenFiles() next to a README.md = %q, want %q", got, want)
+	}
+}
+
+func TestMaxEntries(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"guides/a.md": "# A\n",
+		"guides/b.md": "# B\n",
+		"guides/c.md": "# C\n",
+		"guides/d.md": "# D\n",
+		"x.md":        "# X\n",
+		"y.md":        "# Y\n",
+	})
+	md := listTree(t, dir, testListOptions())
+	md.Title = "Docs"
+	tests := []struct {
+		name  string
+		max   int
+		depth int
+		want  string
+	}{
+		{"unlimited", 0, 1, "# Docs\n\n## guides\n\n" +
+			"- [A](./guides/a.md)\n- [B](./guides/b.md)\n- [C](./guides/c.md)\n- [D](./guides/d.md)\n\n" +
+			"## [X](./x.md)\n\n\n## [Y](./y.md)\n\n"},
+		{"cut", 2, 1, "# Docs\n\n## guides\n\n" +
+			"- [A](./guides/a.md)\n- [B](./guides/b.md)\n- ... and 2 more\n\n" +
+			"## [X](./x.md)\n\n\n## [Y](./y.md)\n\n"},
+		{"limit reached", 4, 1, "# Docs\n\n## guides\n\n" +
+			"- [A](./guides/a.md)\n- [B](./guides/b.md)\n- [C](./guides/c.md)\n- [D](./guides/d.md)\n\n" +
+			"## [X](./x.md)\n\n\n## [Y](./y.md)\n\n"},
+		{"among headings", 1, 2, "# Docs\n\n## guides\n\n\n" +
+			"### [A](./guides/a.md)\n\n\n... and 3 more\n\n" +
+			"## [X](./x.md)\n\n\n## [Y](./y.md)\n\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := testTocOptions()
+			opts.MaxEntries = tt.max
+			opts.HeadingDepth = tt.depth
+			if got := CreateTocTree(md, opts); got != tt.want {
+				t.Errorf("CreateTocTree() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDirAnchorNamespaced(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"v1/api/index.md":  "# Overview\n",
+		"v1/api/auth.md":   "# Auth\n",
+		"v2/api/index.md":  "# Overview\n",
+		"v2/api/auth.md":   "# Auth\n",
+		"v2/api-/extra.md": "# Extra\n",
+	})
+	md := listTree(t, dir, testListOptions())
+	tests := []struct {
+		name       string
+		namespaced bool
+		v1, v2     string
+	}{
+		{"title", false, "api", "api"},
+		{"path", true, "file-v1-api", "file-v2-api"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := testTocOptions()
+			opts.NamespaceAnchors = tt.namespaced
+			v1, v2 := md.Children["v1"].Children["api"], md.Children["v2"].Children["api"]
+			if got := DirAnchor(v1, opts); got != tt.v1 {
+				t.Errorf("DirAnchor(v1/api) = %q, want %q", got, tt.v1)
+			}
+			if got := DirAnchor(v2, opts); got != tt.v2 {
+				t.Errorf("DirAnchor(v2/api) = %q, want %q", got, tt.v2)
+			}
+		})
+	}
+	// With namespaced anchors, every file and directory of the tree has its own anchor
+	opts := testTocOptions()
+	opts.NamespaceAnchors = true
+	seen := make(map[string]string)
+	var walk func(node MDFileInfo)
+	walk = func(node MDFileInfo) {
+		for _, child := range node.Children {
+			anchor := FileAnchor(child, opts)
+			if child.IsDir {
+				anchor = DirAnchor(child, opts)
+			}
+			if other, ok := seen[anchor]; ok {
+				t.Errorf("%s and %s share the anchor %q", RelPath(child), other, anchor)
+			}
+			seen[anchor] = RelPath(child)
+			walk(child)
+		}
+	}
+	walk(md)
+}
+
+func TestListMDFilesMaxFiles(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"a.md":      "# A\n",
+		"b/c.md":    "# C\n",
+		"b/d/e.md":  "# E\n",
+		"notes.txt": "not Markdown\n",
+	})
+	tests := []struct {
+		name     string
+		maxFiles int
+		wantErr  bool
+	}{
+		{"no limit", 0, false},
+		{"above", 5, false},
+		{"equal", 3, false},
+		{"below", 2, true},
+	}
+	for _, tt := range tests {
+		for _, parallel := range []int{1, 4} {
+			t.Run(tt.name+"/parallel "+strconv.Itoa(parallel), func(t *testing.T) {
+				opts := testListOptions()
+				opts.MaxFiles = tt.maxFiles
+				opts.Parallel = parallel
+				_, err := ListMDFiles(dir, opts)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("ListMDFiles() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if tt.wantErr && err.Error() != TooManyFilesError(dir, tt.maxFiles).Error() {
+					t.Errorf("ListMDFiles() error = %v, want %v", err, TooManyFilesError(dir, tt.maxFiles))
+				}
+			})
+		}
+	}
+}
+
+func TestListMDFilesUnreadable(t *testing.T) {
+	dir := writeTree(t, map[string]string{"a.md": "# A\n"})
+	// A dangling symlink is listed as a Markdown file which cannot be read
+	if err := os.Symlink(filepath.Join(dir, "missing.md"), filepath.Join(dir, "broken.md")); err != nil {
+		t.Skipf("cannot create a symlink: %v", err)
+	}
+	for _, parallel := range []int{1, 4} {
+		t.Run("parallel "+strconv.Itoa(parallel), func(t *testing.T) {
+			opts := testListOptions()
+			opts.Parallel = parallel
+			if _, err := ListMDFiles(dir, opts); err == nil || !strings.Contains(err.Error(), "broken.md") {
+				t.Errorf("ListMDFiles() error = %v, want the read error of broken.md", err)
+			}
+		})
+	}
+}
+
+func TestListMDFilesHugo(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"_index.md":       "---\ntitle: Site\n---\n",
+		"posts/_index.md": "---\ntitle: Blog Posts\nweight: 2\n---\n",
+		"posts/a.md":      "---\nweight: 2\n---\n# A\n",
+		"posts/b.md":      "---\nweight: 1\n---\n# B\n",
+		"docs/_index.md":  "---\nweight: 1\n---\n# Documentation\n",
+		"docs/x.md":       "# X\n",
+	})
+	tests := []struct {
+		name string
+		hugo bool
+		want []string
+	}{
+		{"plain", false, []string{"_index.md", "docs/_index.md", "docs/x.md", "posts/_index.md", "posts/a.md", "posts/b.md"}},
+		{"sections", true, []string{"docs/x.md", "posts/a.md", "posts/b.md"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := testListOptions()
+			opts.Hugo = tt.hugo
+			if got := listedPaths(listTree(t, dir, opts)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ListMDFiles() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	opts := testListOptions()
+	opts.Hugo = true
+	md := listTree(t, dir, opts)
+	md.Title = "Docs"
+	tocOpts := testTocOptions()
+	tocOpts.Sort = SortWeight
+	want := "# Docs\n\n" +
+		"## [Documentation](./docs/_index.md)\n\n" +
+		"- [X](./docs/x.md)\n\n" +
+		"## [Blog Posts](./posts/_index.md)\n\n" +
+		"- [B](./posts/b.md)\n" +
+		"- [A](./posts/a.md)\n"
+	if got := CreateTocTree(md, tocOpts); got != want {
+		t.Errorf("CreateTocTree() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	day := 24 * time.Hour
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"7d", 7 * day, false},
+		{"2w", 14 * day, false},
+		{"1w2d", 9 * day, false},
+		{"1d12h", day + 12*time.Hour, false},
+		{"36h", 36 * time.Hour, false},
+		{"90m", 90 * time.Minute, false},
+		{"", 0, true},
+		{"d", 0, true},
+		{"7 days", 0, true},
+		{"2d1", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseSince(tt.value)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseSince(%q) = %v, %v, want %v, wantErr %v", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestListMDFilesModifiedSince(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"new.md":     "# New\n",
+		"old.md":     "# Old\n",
+		"old/old.md": "# Older\n",
+	})
+	now := time.Now()
+	for name, age := range map[string]time.Duration{"new.md": time.Hour, "old.md": 10 * 24 * time.Hour, "old/old.md": 30 * 24 * time.Hour} {
+		modTime := now.Add(-age)
+		if err := os.Chtimes(filepath.Join(dir, filepath.FromSlash(name)), modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		since string
+		want  []string
+	}{
+		{"1d", []string{"new.md"}},
+		{"2w", []string{"new.md", "old.md"}},
+		{"5w", []string{"new.md", "old/old.md", "old.md"}},
+	}
+	for _, tt := range tests {
+		window, err := ParseSince(tt.since)
+		if err != nil {
+			t.Fatal(err)
+		}
+		opts := testListOptions()
+		opts.ModifiedSince = now.Add(-window)
+		if got := listedPaths(listTree(t, dir, opts)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ListMDFiles() since %s = %q, want %q", tt.since, got, tt.want)
+		}
+	}
+}
+
+func TestListMDFilesSkipDirs(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"intro.md":                   "# Intro\n",
+		"node_modules/pkg/README.md": "# Package\n",
+		"node_modules/pkg/guide.md":  "# Package Guide\n",
+		"guides/vendor/lib.md":       "# Vendored\n",
+		".git/notes.md":              "# Git\n",
+		"build/out.md":               "# Build\n",
+	})
+	tests := []struct {
+		name     string
+		skipDirs []string
+		want     []string
+	}{
+		{"none", nil, []string{".git/notes.md", "build/out.md", "guides/vendor/lib.md", "intro.md", "node_modules/pkg/guide.md"}},
+		{"default", DefaultSkipDirs, []string{"build/out.md", "intro.md"}},
+		{"custom", []string{"build", "pkg"}, []string{".git/notes.md", "guides/vendor/lib.md", "intro.md"}},
+	}
+	for _, tt := range tests {
+		for _, parallel := range []int{1, 4} {
+			t.Run(tt.name+"/parallel "+strconv.Itoa(parallel), func(t *testing.T) {
+				opts := testListOptions()
+				opts.SkipDirs = tt.skipDirs
+				opts.Parallel = parallel
+				if got := listedPaths(listTree(t, dir, opts)); !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("ListMDFiles() = %q, want %q", got, tt.want)
+				}
+			})
+		}
+	}
+	// The root directory itself is walked even when its name is skipped
+	opts := testListOptions()
+	opts.SkipDirs = DefaultSkipDirs
+	if got, want := listedPaths(listTree(t, filepath.Join(dir, "node_modules"), opts)), []string{"pkg/guide.md"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ListMDFiles(node_modules) = %q, want %q", got, want)
+	}
+}
+
+func TestCompact(t *testing.T) {
+	tests := []struct {
+		name  string
+		depth int
+		want  string
+	}{
+		{"default", 1, "# Docs\n" +
+			"## guides\n" +
+			"- advanced\n" +
+			"  - [Scaling](./guides/advanced/scaling.md)\n" +
+			"- [Getting Started](./guides/start.md)\n" +
+			"## [Intro](./intro.md)\n"},
+		{"list only", 0, "# Docs\n" +
+			"- guides\n" +
+			"  - advanced\n" +
+			"    - [Scaling](./guides/advanced/scaling.md)\n" +
+			"  - [Getting Started](./guides/start.md)\n" +
+			"- [Intro](./intro.md)\n"},
+		{"two levels", 2, "# Docs\n" +
+			"## guides\n" +
+			"### advanced\n" +
+			"- [Scaling](./guides/advanced/scaling.md)\n" +
+			"### [Getting Started](./guides/start.md)\n" +
+			"## [Intro](./intro.md)\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := testTocOptions()
+			opts.Compact = true
+			opts.HeadingDepth = tt.depth
+			got := CreateTocTree(sampleDocs(t), opts)
+			if got != tt.want {
+				t.Errorf("CreateTocTree() =\n%s\nwant\n%s", got, tt.want)
+			}
+			if strings.Contains(got, "\n\n") {
+				t.Errorf("CreateTocTree() has blank lines:\n%s", got)
+			}
+		})
+	}
+}
+
+func TestEditURL(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		base string
+		want string
+	}{
+		{"slash", "./guides/start.md", "https://github.com/acme/docs/edit/main/docs/", "https://github.com/acme/docs/edit/main/docs/guides/start.md"},
+		{"no slash", "./intro.md", "https://github.com/acme/docs/edit/main", "https://github.com/acme/docs/edit/main/intro.md"},
+		{"escaped", "./My%20Notes%3F.md", "https://example.com/edit/", "https://example.com/edit/My%20Notes%3F.md"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EditURL(MDFileInfo{Path: tt.path}, tt.base); got != tt.want {
+				t.Errorf("EditURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	opts := testTocOptions()
+	opts.EditBaseURL = "https://github.com/acme/docs/edit/main/"
+	want := "# Docs\n\n" +
+		"## guides\n\n" +
+		"- advanced\n" +
+		"  - [Scaling](./guides/advanced/scaling.md) [" + EditLinkText + "](https://github.com/acme/docs/edit/main/guides/advanced/scaling.md)\n" +
+		"- [Getting Started](./guides/start.md) [" + EditLinkText + "](https://github.com/acme/docs/edit/main/guides/start.md)\n\n" +
+		"## [Intro](./intro.md) [" + EditLinkText + "](https://github.com/acme/docs/edit/main/intro.md)\n\n"
+	if got := CreateTocTree(sampleDocs(t), opts); got != want {
+		t.Errorf("CreateTocTree() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestEscapePath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"./intro.md", "./intro.md"},
+		{filepath.Join(".", "guides", "advanced", "scaling.md"), "guides/advanced/scaling.md"},
+		{"." + string(filepath.Separator) + filepath.Join("guides", "my notes.md"), "./guides/my%20notes.md"},
+		{"./q&a/what?#1.md", "./q&a/what%3F%231.md"},
+	}
+	if runtime.GOOS == "windows" {
+		tests = append(tests, struct{ path, want string }{`.\guides\start.md`, "./guides/start.md"})
+	} else {
+		// A backslash is a valid character of a file name
+		tests = append(tests, struct{ path, want string }{`./back\slash.md`, "./back%5Cslash.md"})
+	}
+	for _, tt := range tests {
+		if got := EscapePath(tt.path); got != tt.want {
+			t.Errorf("EscapePath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+	got := CreateTocTree(sampleDocs(t), testTocOptions())
+	if strings.Contains(got, "%5C") {
+		t.Errorf("CreateTocTree() has backslashes in its links:\n%s", got)
+	}
+}
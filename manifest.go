@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Manifest lists the discovered files, it is the output of the manifest format and the
+// baseline compared by the `-diff-manifest` flag.
+type Manifest struct {
+	Files []ManifestFile `json:"files"`
+}
+
+// ManifestFile is a file of a Manifest.
+type ManifestFile struct {
+	Path  string `json:"path"`
+	Title string `json:"title"`
+}
+
+// BuildManifest lists the files of the tree in rendering order.
+//
+// Parameters:
+// - md: the MDFileInfo object representing the root directory.
+// - opts: the options used to render the TOC.
+//
+// Returns:
+// - Manifest: the manifest of the files.
+func BuildManifest(md MDFileInfo, opts TocOptions) Manifest {
+	manifest := Manifest{Files: []ManifestFile{}}
+	for _, entry := range FlattenFiles(md, opts) {
+		manifest.Files = append(manifest.Files, ManifestFile{Path: RelPath(entry.File), Title: entry.File.Title})
+	}
+	return manifest
+}
+
+// CreateManifest generates the JSON manifest of the files, see BuildManifest.
+//
+// Parameters:
+// - md: the MDFileInfo object representing the root directory.
+// - opts: the options used to render the TOC.
+//
+// Returns:
+// - string: the generated JSON.
+func CreateManifest(md MDFileInfo, opts TocOptions) string {
+	out, _ := json.MarshalIndent(BuildManifest(md, opts), "", opts.Indent)
+	return string(out) + "\n"
+}
+
+// LoadManifest reads a manifest written with the manifest format.
+//
+// Parameters:
+// - filePath: the path of the manifest.
+//
+// Returns:
+// - Manifest: the manifest.
+// - error: an error if the file could not be read or parsed.
+func LoadManifest(filePath string) (Manifest, error) {
+	var manifest Manifest
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return manifest, err
+	}
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return manifest, fmt.Errorf("%s: %w", filePath, err)
+	}
+	return manifest, nil
+}
+
+// CreateManifestDiff generates a Markdown report of the files added, removed and retitled since
+// the baseline manifest, with one section per kind of change. Every section is sorted by path.
+//
+// Parameters:
+// - md: the MDFileInfo object representing the root directory.
+// - baseline: the manifest the files are compared to.
+// - opts: the options used to render the TOC.
+//
+// Returns:
+// - string: the generated report.
+func CreateManifestDiff(md MDFileInfo, baseline Manifest, opts TocOptions) string {
+	before := make(map[string]string, len(baseline.Files))
+	for _, file := range baseline.Files {
+		before[file.Path] = file.Title
+	}
+	var added, removed, retitled []string
+	current := make(map[string]bool)
+	for _, entry := range FlattenFiles(md, opts) {
+		path := RelPath(entry.File)
+		current[path] = true
+		title, ok := before[path]
+		switch {
+		case !ok:
+			added = append(added, FormatLink(entry.File, opts))
+		case title != entry.File.Title:
+			retitled = append(retitled, fmt.Sprintf("%s, was %q", FormatLink(entry.File, opts), title))
+		}
+	}
+	for _, file := range baseline.Files {
+		if !current[file.Path] {
+			removed = append(removed, fmt.Sprintf("%s (%s)", file.Path, file.Title))
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("# " + md.Title + " changes\n")
+	for _, section := range []struct {
+		title   string
+		entries []string
+	}{{"Added", added}, {"Removed", removed}, {"Retitled", retitled}} {
+		if len(section.entries) == 0 {
+			continue
+		}
+		sort.Strings(section.entries)
+		sb.WriteString("\n## " + section.title + "\n\n")
+		for _, entry := range section.entries {
+			sb.WriteString(ListMarker + entry + "\n")
+		}
+	}
+	if len(added)+len(removed)+len(retitled) == 0 {
+		sb.WriteString("\nNo changes.\n")
+	}
+	return sb.String()
+}
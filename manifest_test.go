@@ -0,0 +1,68 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestManifestRoundTrip(t *testing.T) {
+	md := sampleDocs(t)
+	opts := testTocOptions()
+	path := filepath.Join(t.TempDir(), "manifest.json")
+	if err := os.WriteFile(path, []byte(CreateManifest(md, opts)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadManifest(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Manifest{Files: []ManifestFile{
+		{Path: "guides/advanced/scaling.md", Title: "Scaling"},
+		{Path: "guides/start.md", Title: "Getting Started"},
+		{Path: "intro.md", Title: "Intro"},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadManifest() = %+v, want %+v", got, want)
+	}
+	if err := os.WriteFile(path, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadManifest(path); err == nil {
+		t.Error("LoadManifest() of invalid JSON returned no error")
+	}
+}
+
+func TestCreateManifestDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		baseline []ManifestFile
+		want     string
+	}{
+		{"no changes", []ManifestFile{
+			{Path: "guides/advanced/scaling.md", Title: "Scaling"},
+			{Path: "guides/start.md", Title: "Getting Started"},
+			{Path: "intro.md", Title: "Intro"},
+		}, "# Docs changes\n\nNo changes.\n"},
+		{"delta", []ManifestFile{
+			{Path: "guides/start.md", Title: "Start"},
+			{Path: "intro.md", Title: "Intro"},
+			{Path: "old.md", Title: "Old"},
+		}, "# Docs changes\n" +
+			"\n## Added\n\n" +
+			"- [Scaling](./guides/advanced/scaling.md)\n" +
+			"\n## Removed\n\n" +
+			"- old.md (Old)\n" +
+			"\n## Retitled\n\n" +
+			"- [Getting Started](./guides/start.md), was \"Start\"\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CreateManifestDiff(sampleDocs(t), Manifest{Files: tt.baseline}, testTocOptions())
+			if got != tt.want {
+				t.Errorf("CreateManifestDiff() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// yamlPlainRegex matches the scalars which can be written unquoted in YAML.
+var yamlPlainRegex = regexp.MustCompile(`^[\pL\pN_][\pL\pN _./()-]*$`)
+
+// CreateMkDocsNav generates the `nav:` section of a mkdocs.yml. Every file is a `Title: path`
+// item with its path relative to the docs directory, every directory is a section nesting its
+// children, preceded by the path of its index page when it has a LinkPath.
+//
+// Parameters:
+// - md: the MDFileInfo object representing the root directory, which must be the docs directory.
+// - opts: the options used to render the TOC.
+//
+// Returns:
+// - string: the generated YAML.
+func CreateMkDocsNav(md MDFileInfo, opts TocOptions) string {
+	var sb strings.Builder
+	sb.WriteString("nav:\n")
+	writeMkDocsNav(&sb, md, 1, opts)
+	return sb.String()
+}
+
+// writeMkDocsNav writes the children of a directory as YAML list items indented depth times.
+func writeMkDocsNav(sb *strings.Builder, md MDFileInfo, depth int, opts TocOptions) {
+	indent := strings.Repeat("  ", depth)
+	for _, key := range SortedChildKeys(md, opts) {
+		child := md.Children[key]
+		if !child.IsDir && child.Title == "" {
+			// MkDocs titles the untitled pages itself
+			sb.WriteString(indent + "- " + YAMLScalar(RelPath(child)) + "\n")
+			continue
+		}
+		if !child.IsDir {
+			sb.WriteString(indent + "- " + YAMLScalar(child.Title) + ": " + YAMLScalar(RelPath(child)) + "\n")
+			continue
+		}
+		sb.WriteString(indent + "- " + YAMLScalar(child.Title) + ":\n")
+		if child.LinkPath != "" {
+			sb.WriteString(indent + "  - " + YAMLScalar(RelPath(MDFileInfo{Path: child.LinkPath})) + "\n")
+		}
+		writeMkDocsNav(sb, child, depth+1, opts)
+	}
+}
+
+// YAMLScalar returns the string as a YAML scalar, double-quoted unless it is a plain string which
+// YAML would not read as another type, e.g. `true` or `10`.
+//
+// Parameters:
+// - value: the string.
+//
+// Returns:
+// - string: the YAML scalar.
+func YAMLScalar(value string) string {
+	if !yamlPlainRegex.MatchString(value) || strings.HasSuffix(value, " ") {
+		return strconv.Quote(value)
+	}
+	switch strings.ToLower(value) {
+	case "true", "false", "yes", "no", "on", "off", "null", "y", "n":
+		return strconv.Quote(value)
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return strconv.Quote(value)
+	}
+	return value
+}
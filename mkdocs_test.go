@@ -0,0 +1,78 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestCreateMkDocsNav(t *testing.T) {
+	want := "nav:\n" +
+		"  - guides:\n" +
+		"    - advanced:\n" +
+		"      - Scaling: guides/advanced/scaling.md\n" +
+		"    - Getting Started: guides/start.md\n" +
+		"  - Intro: intro.md\n"
+	got := CreateMkDocsNav(sampleDocs(t), testTocOptions())
+	if got != want {
+		t.Errorf("CreateMkDocsNav() =\n%s\nwant\n%s", got, want)
+	}
+	var nav struct {
+		Nav []map[string]interface{} `yaml:"nav"`
+	}
+	if err := yaml.Unmarshal([]byte(got), &nav); err != nil {
+		t.Fatalf("CreateMkDocsNav() is not valid YAML: %v", err)
+	}
+	wantNav := []map[string]interface{}{
+		{"guides": []interface{}{
+			map[string]interface{}{"advanced": []interface{}{
+				map[string]interface{}{"Scaling": "guides/advanced/scaling.md"},
+			}},
+			map[string]interface{}{"Getting Started": "guides/start.md"},
+		}},
+		{"Intro": "intro.md"},
+	}
+	if !reflect.DeepEqual(nav.Nav, wantNav) {
+		t.Errorf("nav = %v, want %v", nav.Nav, wantNav)
+	}
+}
+
+func TestCreateMkDocsNavIndexAndUntitled(t *testing.T) {
+	md := MDFileInfo{IsDir: true, Children: map[string]MDFileInfo{
+		"guides": {Name: "guides", IsDir: true, Title: "Guides: 2024", LinkPath: "./guides/README.md", Children: map[string]MDFileInfo{
+			"a.md": {Name: "a.md", Path: "./guides/a.md"},
+		}},
+	}}
+	want := "nav:\n" +
+		"  - \"Guides: 2024\":\n" +
+		"    - guides/README.md\n" +
+		"    - guides/a.md\n"
+	if got := CreateMkDocsNav(md, testTocOptions()); got != want {
+		t.Errorf("CreateMkDocsNav() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestYAMLScalar(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"Getting Started", "Getting Started"},
+		{"guides/start.md", "guides/start.md"},
+		{"true", `"true"`},
+		{"No", `"No"`},
+		{"10", `"10"`},
+		{"1.5", `"1.5"`},
+		{"A: B", `"A: B"`},
+		{"#tag", `"#tag"`},
+		{"trailing ", `"trailing "`},
+		{"", `""`},
+		{`say "hi"`, `"say \"hi\""`},
+	}
+	for _, tt := range tests {
+		if got := YAMLScalar(tt.value); got != tt.want {
+			t.Errorf("YAMLScalar(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
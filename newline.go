@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"strings"
+)
+
+// Trailing newline policies supported by the `-trailing-newline` flag.
+const (
+	NewlineSingle = "single"
+	NewlineNone   = "none"
+)
+
+// ApplyTrailingNewline ends the output with exactly one newline with NewlineSingle, or with
+// none with NewlineNone. An empty output is left empty.
+//
+// Parameters:
+// - output: the output.
+// - policy: NewlineSingle or NewlineNone.
+//
+// Returns:
+// - string: the output with its trailing newlines replaced.
+func ApplyTrailingNewline(output, policy string) string {
+	output = strings.TrimRight(output, "\n")
+	if output == "" || policy == NewlineNone {
+		return output
+	}
+	return output + "\n"
+}
+
+// newlineWriter applies a trailing newline policy to a stream: the newlines are held back until
+// more content follows them, Close writes the trailing newline of the policy.
+type newlineWriter struct {
+	w       io.Writer
+	policy  string
+	pending int  // the number of newlines held back
+	written bool // whether anything but newlines was written
+}
+
+// Write writes p to the underlying writer, holding back its trailing newlines.
+func (n *newlineWriter) Write(p []byte) (int, error) {
+	content := bytes.TrimRight(p, "\n")
+	if len(content) > 0 {
+		if _, err := io.WriteString(n.w, strings.Repeat("\n", n.pending)); err != nil {
+			return 0, err
+		}
+		if _, err := n.w.Write(content); err != nil {
+			return 0, err
+		}
+		n.pending = 0
+		n.written = true
+	}
+	n.pending += len(p) - len(content)
+	return len(p), nil
+}
+
+// Close writes the trailing newline of the policy, if anything was written.
+func (n *newlineWriter) Close() error {
+	if !n.written || n.policy == NewlineNone {
+		return nil
+	}
+	_, err := io.WriteString(n.w, "\n")
+	return err
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTrailingNewline(t *testing.T) {
+	tests := []struct {
+		name   string
+		chunks []string
+		policy string
+		want   string
+	}{
+		{"single", []string{"# Docs\n\n"}, NewlineSingle, "# Docs\n"},
+		{"single added", []string{"# Docs"}, NewlineSingle, "# Docs\n"},
+		{"none", []string{"# Docs\n\n"}, NewlineNone, "# Docs"},
+		{"empty", nil, NewlineSingle, ""},
+		{"only newlines", []string{"\n\n"}, NewlineSingle, ""},
+		{"inner newlines kept", []string{"# Docs\n\n", "- [A](a.md)\n", "\n", "- [B](b.md)\n\n"}, NewlineSingle, "# Docs\n\n- [A](a.md)\n\n- [B](b.md)\n"},
+		{"split chunks", []string{"# Do", "cs\n", "\n", "\n"}, NewlineNone, "# Docs"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := strings.Join(tt.chunks, "")
+			if got := ApplyTrailingNewline(output, tt.policy); got != tt.want {
+				t.Errorf("ApplyTrailingNewline() = %q, want %q", got, tt.want)
+			}
+			var sb strings.Builder
+			nw := &newlineWriter{w: &sb, policy: tt.policy}
+			for _, chunk := range tt.chunks {
+				if n, err := nw.Write([]byte(chunk)); err != nil || n != len(chunk) {
+					t.Fatalf("Write() = %d, %v, want %d, nil", n, err, len(chunk))
+				}
+			}
+			if err := nw.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if got := sb.String(); got != tt.want {
+				t.Errorf("newlineWriter = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
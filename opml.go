@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/xml"
+)
+
+type opmlDocument struct {
+	XMLName xml.Name      `xml:"opml"`
+	Version string        `xml:"version,attr"`
+	Title   string        `xml:"head>title"`
+	Body    []opmlOutline `xml:"body>outline"`
+}
+
+type opmlOutline struct {
+	Text     string        `xml:"text,attr"`
+	Type     string        `xml:"type,attr,omitempty"`
+	URL      string        `xml:"url,attr,omitempty"`
+	Outlines []opmlOutline `xml:"outline"`
+}
+
+// CreateOPML generates the TOC as an OPML 2.0 outline, every node of the tree becomes an
+// `<outline>` element nested in the element of its parent, and files are `link` outlines
+// pointing to their path.
+//
+// Parameters:
+// - md: the MDFileInfo object representing the root directory.
+// - opts: the options used to render the TOC.
+//
+// Returns:
+// - string: the generated OPML document.
+func CreateOPML(md MDFileInfo, opts TocOptions) string {
+	doc := opmlDocument{
+		Version: "2.0",
+		Title:   md.Title,
+		Body:    opmlOutlines(md, opts),
+	}
+	out, err := xml.MarshalIndent(doc, "", opts.Indent)
+	if err != nil {
+		return ""
+	}
+	return xml.Header + string(out) + "\n"
+}
+
+// opmlOutlines converts the children of md into OPML outlines.
+func opmlOutlines(md MDFileInfo, opts TocOptions) []opmlOutline {
+	var outlines []opmlOutline
+	for _, key := range SortedChildKeys(md, opts) {
+		child := md.Children[key]
+		outline := opmlOutline{Text: child.Title}
+		if child.IsDir {
+			outline.Outlines = opmlOutlines(child, opts)
+			if child.LinkPath != "" {
+				outline.Type = "link"
+				outline.URL = LinkTarget(MDFileInfo{Path: child.LinkPath}, opts)
+			}
+		} else {
+			outline.Type = "link"
+			outline.URL = LinkTarget(child, opts)
+		}
+		outlines = append(outlines, outline)
+	}
+	return outlines
+}
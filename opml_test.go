@@ -0,0 +1,38 @@
+package main
+
+import (
+	"encoding/xml"
+	"testing"
+)
+
+func TestCreateOPML(t *testing.T) {
+	md := sampleDocs(t)
+	md.Title = "Docs & more"
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<opml version="2.0">
+  <head>
+    <title>Docs &amp; more</title>
+  </head>
+  <body>
+    <outline text="guides">
+      <outline text="advanced">
+        <outline text="Scaling" type="link" url="./guides/advanced/scaling.md"></outline>
+      </outline>
+      <outline text="Getting Started" type="link" url="./guides/start.md"></outline>
+    </outline>
+    <outline text="Intro" type="link" url="./intro.md"></outline>
+  </body>
+</opml>
+`
+	got := CreateOPML(md, testTocOptions())
+	if got != want {
+		t.Errorf("CreateOPML() =\n%s\nwant\n%s", got, want)
+	}
+	var doc opmlDocument
+	if err := xml.Unmarshal([]byte(got), &doc); err != nil {
+		t.Fatalf("invalid OPML: %v", err)
+	}
+	if doc.Title != md.Title || len(doc.Body) != 2 || len(doc.Body[0].Outlines) != 2 {
+		t.Errorf("parsed OPML = %+v", doc)
+	}
+}
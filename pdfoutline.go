@@ -0,0 +1,48 @@
+package main
+
+import (
+	"strings"
+)
+
+// PageBreak is the page break emitted between the top-level sections of the PDF outline,
+// understood by Pandoc when converting to PDF through LaTeX.
+const PageBreak = `\newpage`
+
+// CreatePDFOutline generates a printable outline: every directory and file is a heading numbered
+// hierarchically, e.g. `## 1.2 Title`, whose level follows its depth. The levels deeper than
+// opts.HeadingDepth are numbered list items instead. Every top-level section starts on a new page.
+//
+// Parameters:
+// - md: the MDFileInfo object representing the root directory.
+// - opts: the options used to render the TOC.
+//
+// Returns:
+// - string: the generated Markdown.
+func CreatePDFOutline(md MDFileInfo, opts TocOptions) string {
+	var sb strings.Builder
+	sb.WriteString(RootHeading(md, opts))
+	listed := false
+	var walk func(node MDFileInfo)
+	walk = func(node MDFileInfo) {
+		for _, key := range SortedChildKeys(node, opts) {
+			child := node.Children[key]
+			if child.Level > opts.HeadingDepth {
+				if !listed {
+					sb.WriteString("\n")
+					listed = true
+				}
+				sb.WriteString(strings.Repeat(opts.Indent, child.Level-opts.HeadingDepth-1) + ListMarker + EntryText(child, opts) + "\n")
+				walk(child)
+				continue
+			}
+			if child.Level == 1 {
+				sb.WriteString("\n" + PageBreak + "\n")
+			}
+			sb.WriteString("\n" + HeadingMarker(child.Level+1) + " " + EntryText(child, opts) + "\n")
+			listed = false
+			walk(child)
+		}
+	}
+	walk(NumberSections(md, "", opts))
+	return sb.String()
+}
@@ -0,0 +1,37 @@
+package main
+
+import "testing"
+
+func TestCreatePDFOutline(t *testing.T) {
+	tests := []struct {
+		name  string
+		depth int
+		want  string
+	}{
+		{"headings", FormatHeadingDepths[FormatPDFOutline], "# Docs\n\n" +
+			PageBreak + "\n\n" +
+			"## 1 guides\n\n" +
+			"### 1.1 advanced\n\n" +
+			"#### [1.1.1 Scaling](./guides/advanced/scaling.md)\n\n" +
+			"### [1.2 Getting Started](./guides/start.md)\n\n" +
+			PageBreak + "\n\n" +
+			"## [2 Intro](./intro.md)\n"},
+		{"lists below the heading depth", 1, "# Docs\n\n" +
+			PageBreak + "\n\n" +
+			"## 1 guides\n\n" +
+			"- 1.1 advanced\n" +
+			"  - [1.1.1 Scaling](./guides/advanced/scaling.md)\n" +
+			"- [1.2 Getting Started](./guides/start.md)\n\n" +
+			PageBreak + "\n\n" +
+			"## [2 Intro](./intro.md)\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := testTocOptions()
+			opts.HeadingDepth = tt.depth
+			if got := CreatePDFOutline(sampleDocs(t), opts); got != tt.want {
+				t.Errorf("CreatePDFOutline() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// PostProcessor transforms the final output before it is written.
+type PostProcessor func(output string) (string, error)
+
+// ShellPostProcessor returns a PostProcessor piping the output through a shell command, which
+// reads it on stdin and writes the transformed output on stdout, e.g. `sed 's/foo/bar/'`.
+// The command runs with `sh -c`, or `cmd /C` on Windows, and its stderr is passed through.
+//
+// Parameters:
+// - command: the shell command.
+//
+// Returns:
+// - PostProcessor: the post-processor running the command.
+func ShellPostProcessor(command string) PostProcessor {
+	return func(output string) (string, error) {
+		var cmd *exec.Cmd
+		if runtime.GOOS == "windows" {
+			cmd = exec.Command("cmd", "/C", command)
+		} else {
+			cmd = exec.Command("sh", "-c", command)
+		}
+		var stdout bytes.Buffer
+		cmd.Stdin = strings.NewReader(output)
+		cmd.Stdout = &stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return "", fmt.Errorf("postprocess %q: %w", command, err)
+		}
+		return stdout.String(), nil
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestShellPostProcessor(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the commands are POSIX shell commands")
+	}
+	tests := []struct {
+		name    string
+		command string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"identity", "cat", "# Docs\n", "# Docs\n", false},
+		{"filter", "sed 's/Docs/Guides/'", "# Docs\n- [Docs](a.md)\n", "# Guides\n- [Guides](a.md)\n", false},
+		{"pipeline", "tr a-z A-Z | head -n 1", "# docs\nmore\n", "# DOCS\n", false},
+		{"failure", "exit 3", "# Docs\n", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ShellPostProcessor(tt.command)(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ShellPostProcessor(%q) error = %v, wantErr %v", tt.command, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ShellPostProcessor(%q) = %q, want %q", tt.command, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WritePreview writes the output to w with a line number before every line, e.g. `  12 | text`.
+// When the current content of the output file is given, the lines which differ from the lines of
+// the same number in it are marked with a `*` after their number.
+//
+// Parameters:
+// - w: the writer the preview is written to, usually os.Stderr.
+// - output: the generated output.
+// - current: the lines of the existing output file, nil not to mark the differences.
+func WritePreview(w io.Writer, output string, current []string) {
+	lines := strings.Split(strings.TrimSuffix(output, "\n"), "\n")
+	width := len(fmt.Sprint(len(lines)))
+	for i, line := range lines {
+		mark := " "
+		if current != nil && (i >= len(current) || current[i] != line) {
+			mark = "*"
+		}
+		fmt.Fprintf(w, "%*d%s| %s\n", width, i+1, mark, line)
+	}
+}
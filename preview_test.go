@@ -0,0 +1,31 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWritePreview(t *testing.T) {
+	output := strings.Repeat("line\n", 9) + "# Docs\n"
+	tests := []struct {
+		name    string
+		output  string
+		current []string
+		want    string
+	}{
+		{"numbered", "# Docs\n\n- a\n", nil, "1 | # Docs\n2 | \n3 | - a\n"},
+		{"differences", "# Docs\n- a\n- b\n", []string{"# Docs", "- b"}, "1 | # Docs\n2*| - a\n3*| - b\n"},
+		{"no difference", "# Docs\n", []string{"# Docs"}, "1 | # Docs\n"},
+		{"aligned numbers", output, nil, " 1 | line\n 2 | line\n 3 | line\n 4 | line\n 5 | line\n 6 | line\n 7 | line\n 8 | line\n 9 | line\n10 | # Docs\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			WritePreview(&buf, tt.output, tt.current)
+			if got := buf.String(); got != tt.want {
+				t.Errorf("WritePreview() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
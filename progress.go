@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Progress reports the number of files processed while building the tree, on a single line
+// rewritten with a carriage return. A nil Progress reports nothing. It is safe for concurrent use.
+type Progress struct {
+	w     io.Writer
+	total int
+	done  int
+	mu    sync.Mutex
+}
+
+// NewProgress returns a Progress writing to w, usually os.Stderr so the TOC on stdout is untouched.
+//
+// Parameters:
+// - w: the writer the progress is written to.
+//
+// Returns:
+// - *Progress: the progress indicator.
+func NewProgress(w io.Writer) *Progress {
+	return &Progress{w: w}
+}
+
+// SetTotal sets the number of files to process when it is known upfront, e.g. from a remote listing,
+// so the progress is reported as `processed/total`.
+func (p *Progress) SetTotal(total int) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.total = total
+}
+
+// Add reports one more processed file.
+func (p *Progress) Add() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done++
+	if p.total > 0 {
+		fmt.Fprintf(p.w, "\rprocessed %d/%d files", p.done, p.total)
+	} else {
+		fmt.Fprintf(p.w, "\rprocessed %d files", p.done)
+	}
+}
+
+// Finish ends the progress line, if anything was reported, and restarts the count so that the
+// next listing is reported on a line of its own.
+func (p *Progress) Finish() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.done > 0 {
+		fmt.Fprintln(p.w)
+	}
+	p.done, p.total = 0, 0
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProgress(t *testing.T) {
+	tests := []struct {
+		name  string
+		total int
+		adds  int
+		want  string
+	}{
+		{"nothing", 0, 0, ""},
+		{"counter", 0, 2, "\rprocessed 1 files\rprocessed 2 files\n"},
+		{"total", 3, 2, "\rprocessed 1/3 files\rprocessed 2/3 files\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			p := NewProgress(&buf)
+			p.SetTotal(tt.total)
+			for i := 0; i < tt.adds; i++ {
+				p.Add()
+			}
+			p.Finish()
+			if got := buf.String(); got != tt.want {
+				t.Errorf("Progress = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProgressFinishRestarts(t *testing.T) {
+	var buf bytes.Buffer
+	p := NewProgress(&buf)
+	p.SetTotal(2)
+	p.Add()
+	p.Finish()
+	p.Add()
+	p.Finish()
+	if got, want := buf.String(), "\rprocessed 1/2 files\n\rprocessed 1 files\n"; got != want {
+		t.Errorf("Progress = %q, want %q", got, want)
+	}
+}
+
+func TestFixTitlesProgress(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"a.md":       "# A\n",
+		"getting.md": "text\n",
+	})
+	stderr, err := os.Create(filepath.Join(t.TempDir(), "stderr"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stderr.Close()
+	oldStderr := os.Stderr
+	os.Stderr = stderr
+	runMain(t, "-dir", dir, "-t", "Docs", "-progress", "-fix-titles")
+	os.Stderr = oldStderr
+	content, err := os.ReadFile(stderr.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The files are listed again after the fix, each listing ends its own progress line
+	lines := strings.Split(string(content), "\n")
+	if len(lines) != 4 || !strings.HasSuffix(lines[0], "processed 2 files") || !strings.Contains(lines[1], "inserted # Getting") ||
+		!strings.HasSuffix(lines[2], "processed 2 files") || lines[3] != "" {
+		t.Errorf("stderr = %q, want the progress of both listings on their own lines", content)
+	}
+}
+
+func TestProgressNil(t *testing.T) {
+	var p *Progress
+	p.SetTotal(1)
+	p.Add()
+	p.Finish()
+}
+
+func TestListMDFilesProgress(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"a.md":     "# A\n",
+		"b/c.md":   "# C\n",
+		"b/d/e.md": "# E\n",
+	})
+	var buf bytes.Buffer
+	opts := testListOptions()
+	opts.Progress = NewProgress(&buf)
+	opts.Parallel = 4
+	listTree(t, dir, opts)
+	opts.Progress.Finish()
+	if got := buf.String(); !strings.HasSuffix(got, "\rprocessed 3 files\n") {
+		t.Errorf("Progress = %q, want it to end with 3 processed files", got)
+	}
+	// The progress of the command goes to stderr, which is discarded
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer devNull.Close()
+	oldStderr := os.Stderr
+	os.Stderr = devNull
+	out := runMain(t, "-dir", dir, "-t", "Docs", "-progress")
+	os.Stderr = oldStderr
+	if strings.Contains(out, "processed") {
+		t.Errorf("stdout contains the progress:\n%s", out)
+	}
+}
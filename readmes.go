@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// GeneratedReadmeMarker is the first line of the README.md files written by WriteDirReadmes,
+// which tells them apart from the hand-written ones.
+const GeneratedReadmeMarker = "<!-- Generated by mdtocgen, do not edit -->"
+
+// WriteDirReadmes writes a README.md in the root directory and in every directory of the tree,
+// holding the Markdown TOC of the directory alone, with links relative to it. The existing
+// README.md files which were not generated are kept, unless force is true. Every written or kept
+// file is reported to w.
+//
+// Parameters:
+// - dirPath: the directory the tree was listed from.
+// - md: the MDFileInfo object representing the root directory.
+// - opts: the options used to render the TOCs.
+// - force: whether the hand-written README.md files are overwritten.
+// - w: the writer the files are reported to, usually os.Stderr.
+//
+// Returns:
+// - error: an error if a file could not be read or written.
+func WriteDirReadmes(dirPath string, md MDFileInfo, opts TocOptions, force bool, w io.Writer) error {
+	opts.Format = FormatMarkdown
+	return writeDirReadme(dirPath, ".", md, opts, force, w)
+}
+
+func writeDirReadme(dirPath, relDir string, md MDFileInfo, opts TocOptions, force bool, w io.Writer) error {
+	readme := filepath.Join(dirPath, filepath.FromSlash(relDir), "README.md")
+	existing, err := os.ReadFile(readme)
+	switch {
+	case err == nil && !force && !bytes.HasPrefix(existing, []byte(GeneratedReadmeMarker)):
+		fmt.Fprintf(w, "%s: kept, it was not generated, use -force to overwrite it\n", readme)
+	case err != nil && !os.IsNotExist(err):
+		return err
+	default:
+		content := GeneratedReadmeMarker + "\n\n" + RenderToc(Rebase(md, relDir), opts)
+		if err := os.WriteFile(readme, []byte(content), 0644); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "%s: written\n", readme)
+	}
+	for _, key := range SortedChildKeys(md, opts) {
+		if child := md.Children[key]; child.IsDir {
+			if err := writeDirReadme(dirPath, path.Join(relDir, child.Name), child, opts, force, w); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteDirReadmes(t *testing.T) {
+	rootReadme := GeneratedReadmeMarker + "\n\n# Docs\n\n## guides\n\n- [Start](./guides/start.md)\n\n## [Intro](./intro.md)\n\n"
+	guidesReadme := GeneratedReadmeMarker + "\n\n# guides\n\n## [Start](./start.md)\n\n"
+	tests := []struct {
+		name   string
+		files  map[string]string
+		force  bool
+		want   map[string]string
+		report string
+	}{
+		{"new", map[string]string{"intro.md": "# Intro\n", "guides/start.md": "# Start\n"}, false,
+			map[string]string{"README.md": rootReadme, "guides/README.md": guidesReadme}, "guides/README.md: written"},
+		{"regenerated", map[string]string{"intro.md": "# Intro\n", "guides/start.md": "# Start\n", "guides/README.md": GeneratedReadmeMarker + "\nold\n"}, false,
+			map[string]string{"README.md": rootReadme, "guides/README.md": guidesReadme}, "guides/README.md: written"},
+		{"hand-written kept", map[string]string{"intro.md": "# Intro\n", "guides/start.md": "# Start\n", "guides/README.md": "Hand-written\n"}, false,
+			map[string]string{"README.md": rootReadme, "guides/README.md": "Hand-written\n"}, "guides/README.md: kept"},
+		{"hand-written forced", map[string]string{"intro.md": "# Intro\n", "guides/start.md": "# Start\n", "guides/README.md": "Hand-written\n"}, true,
+			map[string]string{"README.md": rootReadme, "guides/README.md": guidesReadme}, "guides/README.md: written"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeTree(t, tt.files)
+			md := listTree(t, dir, testListOptions())
+			md.Title = "Docs"
+			var report bytes.Buffer
+			if err := WriteDirReadmes(dir, md, testTocOptions(), tt.force, &report); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(filepath.ToSlash(report.String()), tt.report) {
+				t.Errorf("report does not contain %q:\n%s", tt.report, report.String())
+			}
+			for name, want := range tt.want {
+				content, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(content) != want {
+					t.Errorf("%s =\n%s\nwant\n%s", name, content, want)
+				}
+			}
+		})
+	}
+}
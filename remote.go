@@ -0,0 +1,232 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// RemoteOptions holds the settings used to fetch a remote listing and its files over HTTP.
+type RemoteOptions struct {
+	RawBase string        // the base URL of the files, the directory of the listing URL if empty
+	Timeout time.Duration // the timeout of every request
+	Headers http.Header   // the headers sent with every request, e.g. Authorization
+}
+
+// HeaderFlag is a repeatable flag collecting `Name: value` HTTP headers.
+type HeaderFlag struct {
+	Header http.Header
+}
+
+// String returns the headers in the `Name: value` form, separated by commas.
+func (h *HeaderFlag) String() string {
+	var headers []string
+	for name, values := range h.Header {
+		for _, value := range values {
+			headers = append(headers, name+": "+value)
+		}
+	}
+	return strings.Join(headers, ", ")
+}
+
+// Set adds a header given in the `Name: value` form.
+func (h *HeaderFlag) Set(value string) error {
+	name, val, ok := strings.Cut(value, ":")
+	if !ok || strings.TrimSpace(name) == "" {
+		return fmt.Errorf("invalid header %q, expected Name: value", value)
+	}
+	if h.Header == nil {
+		h.Header = make(http.Header)
+	}
+	h.Header.Add(strings.TrimSpace(name), strings.TrimSpace(val))
+	return nil
+}
+
+// remoteListing is a listing of remote files, either a GitHub API tree or a list of paths.
+type remoteListing struct {
+	Tree []struct {
+		Path string `json:"path"`
+		Type string `json:"type"`
+	} `json:"tree"`
+}
+
+// ListRemoteMDFiles lists the Markdown files of a remote listing and fetches them to resolve their
+// titles, building the same tree as ListMDFiles does for a local directory.
+//
+// The listing is either a GitHub API tree, i.e. the response of
+// `GET /repos/{owner}/{repo}/git/trees/{ref}?recursive=1`, or a JSON array of file paths.
+// The files are fetched from opts.RawBase joined with their path, e.g.
+// `https://raw.githubusercontent.com/{owner}/{repo}/{ref}/` for a GitHub tree.
+//
+// Parameters:
+// - listURL: the URL of the listing.
+// - opts: the options used to discover the Markdown files.
+// - remote: the options used to fetch the listing and the files.
+//
+// Returns:
+// - MDFileInfo: the root directory and its descendants.
+// - error: an error if the listing or a file could not be fetched.
+func ListRemoteMDFiles(listURL string, opts ListOptions, remote RemoteOptions) (MDFileInfo, error) {
+	root := MDFileInfo{
+		IsDir:    true,
+		Children: make(map[string]MDFileInfo),
+		Level:    0,
+		Title:    "",
+		Path:     ".",
+	}
+	client := &http.Client{Timeout: remote.Timeout}
+	rawBase := remote.RawBase
+	if rawBase == "" {
+		rawBase = listURL
+	} else if !strings.HasSuffix(rawBase, "/") {
+		rawBase += "/"
+	}
+	base, err := url.Parse(rawBase)
+	if err != nil {
+		return root, err
+	}
+
+	content, err := fetchURL(client, listURL, remote.Headers)
+	if err != nil {
+		return root, err
+	}
+	paths, err := parseRemoteListing(content)
+	if err != nil {
+		return root, fmt.Errorf("%s: %w", listURL, err)
+	}
+
+	var (
+		mu sync.Mutex
+		g  errgroup.Group
+	)
+	if opts.Parallel > 1 {
+		g.SetLimit(opts.Parallel)
+	} else {
+		g.SetLimit(1)
+	}
+	newDir := func(parent MDFileInfo, name, relDir string) MDFileInfo {
+		return MDFileInfo{
+			Name:     name,
+			IsDir:    true,
+			Children: make(map[string]MDFileInfo),
+			Level:    parent.Level + 1,
+			Title:    name,
+			Path:     EscapePath("./" + relDir),
+		}
+	}
+	var files []string
+	for _, relPath := range paths {
+		relPath = path.Clean(strings.TrimPrefix(relPath, "/"))
+		// The files are fetched relative to the raw base, with its headers, they must stay under it
+		if relPath == ".." || strings.HasPrefix(relPath, "../") {
+			return root, fmt.Errorf("%s: %q is outside of the listing", listURL, relPath)
+		}
+		if name := path.Base(relPath); path.Ext(name) != ".md" || name == "README.md" || isRemoteExcluded(relPath, opts) {
+			continue
+		}
+		files = append(files, relPath)
+	}
+	if opts.MaxFiles > 0 && len(files) > opts.MaxFiles {
+		return root, TooManyFilesError(listURL, opts.MaxFiles)
+	}
+	opts.Progress.SetTotal(len(files))
+	for _, relPath := range files {
+		relPath := relPath
+		g.Go(func() error {
+			fileURL, err := base.Parse((&url.URL{Path: relPath}).EscapedPath())
+			if err != nil {
+				return err
+			}
+			content, err := fetchURL(client, fileURL.String(), remote.Headers)
+			if err != nil {
+				return err
+			}
+			file, err := newFileInfo(fileURL.String(), "./"+relPath, content, opts)
+			if err != nil {
+				return err
+			}
+			opts.Progress.Add()
+			if !keepFile(&file, opts) {
+				return nil
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			AddFile(root, relPath, file, newDir)
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return root, err
+	}
+	return root, nil
+}
+
+// parseRemoteListing returns the paths of the files of a listing, see ListRemoteMDFiles.
+func parseRemoteListing(content []byte) ([]string, error) {
+	var paths []string
+	if err := json.Unmarshal(content, &paths); err == nil {
+		return paths, nil
+	}
+	var listing remoteListing
+	if err := json.Unmarshal(content, &listing); err != nil {
+		return nil, fmt.Errorf("unsupported listing: %w", err)
+	}
+	for _, entry := range listing.Tree {
+		if entry.Type == "" || entry.Type == "blob" {
+			paths = append(paths, entry.Path)
+		}
+	}
+	return paths, nil
+}
+
+// isRemoteExcluded reports whether a remote file is left out by the include, exclude and language
+// options. A file is also excluded when one of its directories is skipped or matches an exclude pattern.
+func isRemoteExcluded(relPath string, opts ListOptions) bool {
+	if len(opts.Include) > 0 && !MatchesAnyPattern(relPath, false, opts.Include) {
+		return true
+	}
+	if MatchesAnyPattern(relPath, false, opts.Exclude) {
+		return true
+	}
+	for dir := path.Dir(relPath); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		if IsSkippedDir(path.Base(dir), opts.SkipDirs) || MatchesAnyPattern(dir, true, opts.Exclude) {
+			return true
+		}
+	}
+	if opts.Lang != "" {
+		if lang := FileLang(path.Base(relPath)); lang != "" && !strings.EqualFold(lang, opts.Lang) {
+			return true
+		}
+	}
+	return false
+}
+
+// fetchURL returns the body of a GET request to the given URL, failing on non-2xx statuses.
+func fetchURL(client *http.Client, rawURL string, headers http.Header) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range headers {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("GET %s: %s", rawURL, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestListRemoteMDFiles(t *testing.T) {
+	files := map[string]string{
+		"/intro.md":         "# Intro\n",
+		"/guides/start.md":  "# Getting Started\n",
+		"/guides/wip.md":    "---\ndraft: true\n---\n# Work in progress\n",
+		"/guides/legacy.md": "# Legacy API\n",
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/list.json" {
+			w.Write([]byte(`["intro.md", "guides/start.md", "guides/wip.md", "guides/legacy.md", "notes.txt"]`))
+			return
+		}
+		content, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(content))
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name string
+		opts ListOptions
+		want []string
+	}{
+		{"all", ListOptions{}, []string{"Getting Started", "Intro", "Legacy API", "Work in progress"}},
+		{"exclude title", ListOptions{ExcludeTitle: regexp.MustCompile(`^Legacy`)}, []string{"Getting Started", "Intro", "Work in progress"}},
+		{"exclude drafts", ListOptions{MarkDrafts: DraftsExclude}, []string{"Getting Started", "Intro", "Legacy API"}},
+		{"annotate drafts", ListOptions{MarkDrafts: DraftsAnnotate}, []string{"Getting Started", "Intro", "Legacy API", "Work in progress (draft)"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.TitleStrategy = []TitleSource{H1Title}
+			root, err := ListRemoteMDFiles(srv.URL+"/list.json", tt.opts, RemoteOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, entry := range FlattenFiles(root, TocOptions{SortAsc: true}) {
+				got = append(got, entry.File.Title)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("titles = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListRemoteMDFilesOutsideListing(t *testing.T) {
+	var fetched []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetched = append(fetched, r.URL.Path)
+		if r.URL.Path == "/docs/list.json" {
+			w.Write([]byte(`["intro.md", "a/../../secrets.md"]`))
+			return
+		}
+		w.Write([]byte("# Title\n"))
+	}))
+	defer srv.Close()
+
+	_, err := ListRemoteMDFiles(srv.URL+"/docs/list.json", ListOptions{TitleStrategy: []TitleSource{H1Title}}, RemoteOptions{})
+	if err == nil || !strings.Contains(err.Error(), "outside of the listing") {
+		t.Fatalf("ListRemoteMDFiles() error = %v, want an outside of the listing error", err)
+	}
+	if len(fetched) != 1 {
+		t.Errorf("fetched %q, want only the listing", fetched)
+	}
+}
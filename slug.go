@@ -0,0 +1,64 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Slug styles supported by the `-slug-style` flag.
+const (
+	SlugStyleGitHub = "github"
+	SlugStylePandoc = "pandoc"
+)
+
+// Slugify returns the identifier generated for a heading with the given text, as used in
+// `#anchor` links.
+//
+// Parameters:
+// - text: the text of the heading.
+// - style: SlugStyleGitHub or SlugStylePandoc.
+//
+// Returns:
+// - string: the identifier of the heading.
+func Slugify(text, style string) string {
+	if style == SlugStylePandoc {
+		return PandocSlug(text)
+	}
+	return GitHubSlug(text)
+}
+
+// GitHubSlug returns the anchor GitHub generates for a heading: the text is lowercased,
+// every character other than a letter, a digit, a space, a hyphen or an underscore is removed,
+// and spaces become hyphens.
+func GitHubSlug(text string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(text)) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_':
+			sb.WriteRune(r)
+		case r == ' ':
+			sb.WriteRune('-')
+		}
+	}
+	return sb.String()
+}
+
+// PandocSlug returns the identifier pandoc's auto_identifiers extension generates for a heading:
+// every character other than a letter, a digit, an underscore, a hyphen or a period is removed,
+// spaces become hyphens, the text is lowercased and everything up to the first letter is removed.
+// If nothing is left, the identifier is `section`.
+func PandocSlug(text string) string {
+	kept := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsSpace(r) || r == '_' || r == '-' || r == '.' {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, text)
+	slug := strings.TrimLeftFunc(strings.Join(strings.Fields(kept), "-"), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	if slug == "" {
+		return "section"
+	}
+	return slug
+}
@@ -0,0 +1,62 @@
+package main
+
+import "testing"
+
+func TestPandocSlug(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Heading identifiers in HTML", "heading-identifiers-in-html"},
+		{"Maître d'hôtel", "maître-dhôtel"},
+		{"*Dogs*?--in *my* house?", "dogs--in-my-house"},
+		{"[HTML], [S5], or [RTF]?", "html-s5-or-rtf"},
+		{"3. Applications", "applications"},
+		{"33", "section"},
+		{"1.2 Getting started", "getting-started"},
+		{"_private notes", "private-notes"},
+		{"v1.2.3 release", "v1.2.3-release"},
+		{"  Spaces   around  ", "spaces-around"},
+		{"!!!", "section"},
+	}
+	for _, tt := range tests {
+		if got := PandocSlug(tt.text); got != tt.want {
+			t.Errorf("PandocSlug(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestGitHubSlug(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Getting Started", "getting-started"},
+		{"3. Applications", "3-applications"},
+		{"33", "33"},
+		{"API & Reference", "api--reference"},
+		{"snake_case-title", "snake_case-title"},
+		{"Maître d'hôtel", "maître-dhôtel"},
+	}
+	for _, tt := range tests {
+		if got := GitHubSlug(tt.text); got != tt.want {
+			t.Errorf("GitHubSlug(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		style string
+		want  string
+	}{
+		{SlugStyleGitHub, "2-setup"},
+		{SlugStylePandoc, "setup"},
+		{"", "2-setup"},
+	}
+	for _, tt := range tests {
+		if got := Slugify("2 Setup", tt.style); got != tt.want {
+			t.Errorf("Slugify(%q, %q) = %q, want %q", "2 Setup", tt.style, got, tt.want)
+		}
+	}
+}
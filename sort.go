@@ -0,0 +1,305 @@
+package main
+
+import (
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Sort keys supported by the `-sort` flag.
+const (
+	SortName   = "name"
+	SortWeight = "weight"
+	// SortMtime sorts by modification time, a directory by the newest of its files.
+	SortMtime = "mtime"
+	// SortMtimeOldest sorts by modification time, a directory by the oldest of its files.
+	SortMtimeOldest = "mtime-oldest"
+	// SortDepth sorts by nesting depth, the files before the directories, the shallow directories first.
+	SortDepth = "depth"
+	// SortNone applies no sort key. The children are kept in the order they are listed in, which
+	// is their name order whatever the OS and file system, so the output is still reproducible.
+	SortNone = "none"
+	// SortDate sorts by the `date` field of the frontmatter, newest first, a directory by its newest file.
+	SortDate = "date"
+)
+
+// FrontmatterDateLayouts are the layouts the `date` field of the frontmatter is parsed with, in order.
+var FrontmatterDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/01/02",
+	"January 2, 2006",
+	"Jan 2, 2006",
+	"2 January 2006",
+	"2 Jan 2006",
+}
+
+// ChildComparator compares two children of a directory, it returns a negative number when a
+// comes before b, a positive number when b comes before a, and 0 when they are equivalent.
+type ChildComparator func(a, b MDFileInfo) int
+
+// SortComparators maps the names accepted by the `-sort` flag to their comparator.
+var SortComparators = map[string]ChildComparator{
+	SortName:   CompareNames,
+	SortWeight: CompareWeights,
+	SortMtime: func(a, b MDFileInfo) int {
+		return CompareModTimes(a, b, true)
+	},
+	SortMtimeOldest: func(a, b MDFileInfo) int {
+		return CompareModTimes(a, b, false)
+	},
+	SortDepth: CompareDepths,
+	SortNone: func(a, b MDFileInfo) int {
+		return 0
+	},
+	SortDate: CompareDates,
+}
+
+// SortedChildKeys returns the keys of the children of a directory in rendering order.
+//
+// The keys are always sorted by name first so that the result does not depend on
+// map iteration order, then the user's chosen order is applied as a stable sort on top.
+// With opts.Order, the entries listed in the order file come first, see OrderRank. The file the
+// directory uses as its index, see MDFileInfo.IndexPath, is pinned first whatever the order.
+//
+// Parameters:
+// - md: the directory node.
+// - opts: the options used to render the TOC, for the sort key and direction.
+//
+// Returns:
+// - []string: the sorted keys.
+func SortedChildKeys(md MDFileInfo, opts TocOptions) []string {
+	children := md.Children
+	keys := make([]string, 0, len(children))
+	for key := range children {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	compare, ok := SortComparators[opts.Sort]
+	if !ok {
+		compare = CompareNames
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		c := compare(children[keys[i]], children[keys[j]])
+		if opts.SortAsc {
+			return c < 0
+		}
+		return c > 0
+	})
+	if len(opts.Order) > 0 {
+		ranks := make(map[string]int, len(keys))
+		for _, key := range keys {
+			ranks[key] = OrderRank(children[key], opts.Order)
+		}
+		sort.SliceStable(keys, func(i, j int) bool {
+			return ranks[keys[i]] < ranks[keys[j]]
+		})
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		return isIndexFile(md, children[keys[i]]) && !isIndexFile(md, children[keys[j]])
+	})
+	return keys
+}
+
+// LoadOrderFile reads an order file listing the paths of files and directories relative to the
+// root directory, with or without the `.md` extension of the files, in the order they are rendered
+// in, see OrderRank. Blank lines and the lines starting with `#` are skipped.
+//
+// Parameters:
+// - filePath: the path of the order file.
+//
+// Returns:
+// - map[string]int: the position of every listed path, without its `.md` extension.
+// - error: an error if the file could not be read.
+func LoadOrderFile(filePath string) (map[string]int, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	order := make(map[string]int)
+	for _, line := range SplitLines(content) {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key := orderKey(line)
+		if _, ok := order[key]; !ok {
+			order[key] = len(order)
+		}
+	}
+	return order, nil
+}
+
+// OrderRank returns the position of an entry in the order file: the position of its path, or
+// for a directory which is not listed, the first position of the entries below it, so that a
+// directory follows its listed contents. The entries not listed get a rank after all the others,
+// so that they keep the order of the sort key after the listed ones.
+//
+// Parameters:
+// - md: the MDFileInfo object representing the file or directory.
+// - order: the positions loaded by LoadOrderFile.
+//
+// Returns:
+// - int: the rank of the entry.
+func OrderRank(md MDFileInfo, order map[string]int) int {
+	if rank, ok := order[orderKey(RelPath(md))]; ok {
+		return rank
+	}
+	rank := len(order)
+	for _, child := range md.Children {
+		if r := OrderRank(child, order); r < rank {
+			rank = r
+		}
+	}
+	return rank
+}
+
+// orderKey returns the key of a path in the order file: its clean form without `.md`.
+func orderKey(relPath string) string {
+	return strings.TrimSuffix(path.Clean(strings.TrimPrefix(relPath, "./")), ".md")
+}
+
+// isIndexFile reports whether md is the file its directory links to as its index, its IndexPath
+// or LinkPath. The other files named like an index file, e.g. an index.md next to a README.md,
+// are sorted as usual.
+func isIndexFile(dir, md MDFileInfo) bool {
+	if md.IsDir {
+		return false
+	}
+	for _, target := range []string{dir.IndexPath, dir.LinkPath} {
+		if target != "" && RelPath(MDFileInfo{Path: target}) == RelPath(md) {
+			return true
+		}
+	}
+	return false
+}
+
+// CompareNames compares the children by name.
+func CompareNames(a, b MDFileInfo) int {
+	return strings.Compare(a.Name, b.Name)
+}
+
+// CompareWeights compares the children by the `weight` field of their frontmatter, lower first.
+// The children without a weight come after the others, the names break the ties.
+func CompareWeights(a, b MDFileInfo) int {
+	wa, errA := strconv.Atoi(a.Frontmatter["weight"])
+	wb, errB := strconv.Atoi(b.Frontmatter["weight"])
+	switch {
+	case errA == nil && errB == nil && wa != wb:
+		if wa < wb {
+			return -1
+		}
+		return 1
+	case errA == nil && errB != nil:
+		return -1
+	case errA != nil && errB == nil:
+		return 1
+	}
+	return CompareNames(a, b)
+}
+
+// CompareModTimes compares the children by modification time, older first. A directory is dated
+// by the newest or the oldest file below it, so that a changelog-style tree orders its sections
+// by their entries. The names break the ties.
+func CompareModTimes(a, b MDFileInfo, newest bool) int {
+	ta, tb := AggregateModTime(a, newest), AggregateModTime(b, newest)
+	switch {
+	case ta.Before(tb):
+		return -1
+	case tb.Before(ta):
+		return 1
+	}
+	return CompareNames(a, b)
+}
+
+// AggregateModTime returns the modification time of a file, or the newest or the oldest
+// modification time of the files below a directory. It is the zero time for an empty directory.
+//
+// Parameters:
+// - md: the MDFileInfo object representing the file or directory.
+// - newest: whether the newest, rather than the oldest, time of a directory is returned.
+//
+// Returns:
+// - time.Time: the modification time.
+func AggregateModTime(md MDFileInfo, newest bool) time.Time {
+	return aggregateTime(md, newest, func(file MDFileInfo) time.Time {
+		return file.ModTime
+	})
+}
+
+// aggregateTime returns the time of a file, or the newest or the oldest time of the files below
+// a directory, with fileTime returning the time of a file.
+func aggregateTime(md MDFileInfo, newest bool, fileTime func(MDFileInfo) time.Time) time.Time {
+	if !md.IsDir {
+		return fileTime(md)
+	}
+	var result time.Time
+	for _, child := range md.Children {
+		t := aggregateTime(child, newest, fileTime)
+		if t.IsZero() {
+			continue
+		}
+		if result.IsZero() || (newest && t.After(result)) || (!newest && t.Before(result)) {
+			result = t
+		}
+	}
+	return result
+}
+
+// CompareDates compares the children by the date of their frontmatter, newest first, see FileDate.
+// A directory is dated by the newest file below it. The names break the ties.
+func CompareDates(a, b MDFileInfo) int {
+	ta, tb := aggregateTime(a, true, FileDate), aggregateTime(b, true, FileDate)
+	switch {
+	case ta.After(tb):
+		return -1
+	case tb.After(ta):
+		return 1
+	}
+	return CompareNames(a, b)
+}
+
+// FileDate returns the `date` field of the frontmatter of a file, parsed with one of the
+// FrontmatterDateLayouts, or its modification time when it has no valid date.
+func FileDate(md MDFileInfo) time.Time {
+	if value := md.Frontmatter["date"]; value != "" {
+		for _, layout := range FrontmatterDateLayouts {
+			if t, err := time.Parse(layout, value); err == nil {
+				return t
+			}
+		}
+	}
+	return md.ModTime
+}
+
+// CompareDepths compares the children by the depth of their subtree, shallow first: the files,
+// then the directories which only hold files, and so on. The names break the ties.
+func CompareDepths(a, b MDFileInfo) int {
+	da, db := SubtreeDepth(a), SubtreeDepth(b)
+	switch {
+	case da < db:
+		return -1
+	case da > db:
+		return 1
+	}
+	return CompareNames(a, b)
+}
+
+// SubtreeDepth returns the number of levels below md: 0 for a file or an empty directory,
+// 1 for a directory which only holds files, and so on.
+func SubtreeDepth(md MDFileInfo) int {
+	depth := 0
+	for _, child := range md.Children {
+		if d := SubtreeDepth(child) + 1; d > depth {
+			depth = d
+		}
+	}
+	return depth
+}
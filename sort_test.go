@@ -0,0 +1,276 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// weighted returns a file child with the given weight, none when empty.
+func weighted(name, weight string) MDFileInfo {
+	md := MDFileInfo{Name: name, Path: "./" + name, Frontmatter: map[string]string{}}
+	if weight != "" {
+		md.Frontmatter["weight"] = weight
+	}
+	return md
+}
+
+func TestSortedChildKeysWeight(t *testing.T) {
+	tests := []struct {
+		name     string
+		children []MDFileInfo
+		asc      bool
+		want     []string
+	}{
+		{
+			name:     "lower weight first",
+			children: []MDFileInfo{weighted("a.md", "30"), weighted("b.md", "10"), weighted("c.md", "20")},
+			asc:      true,
+			want:     []string{"b.md", "c.md", "a.md"},
+		},
+		{
+			name:     "unweighted last",
+			children: []MDFileInfo{weighted("a.md", ""), weighted("b.md", "5"), weighted("c.md", "not a number")},
+			asc:      true,
+			want:     []string{"b.md", "a.md", "c.md"},
+		},
+		{
+			name:     "names break ties",
+			children: []MDFileInfo{weighted("c.md", "1"), weighted("a.md", "1"), weighted("b.md", "1")},
+			asc:      true,
+			want:     []string{"a.md", "b.md", "c.md"},
+		},
+		{
+			name:     "descending",
+			children: []MDFileInfo{weighted("a.md", "1"), weighted("b.md", "2")},
+			want:     []string{"b.md", "a.md"},
+		},
+		{
+			name:     "index file pinned first",
+			children: []MDFileInfo{weighted("a.md", "1"), weighted("README.md", "9")},
+			asc:      true,
+			want:     []string{"README.md", "a.md"},
+		},
+		{
+			name:     "other index names sorted",
+			children: []MDFileInfo{weighted("a.md", "1"), weighted("index.md", "9")},
+			asc:      true,
+			want:     []string{"a.md", "index.md"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			children := make(map[string]MDFileInfo, len(tt.children))
+			for _, child := range tt.children {
+				children[child.Name] = child
+			}
+			dir := MDFileInfo{IsDir: true, Children: children, IndexPath: "./README.md"}
+			got := SortedChildKeys(dir, TocOptions{Sort: SortWeight, SortAsc: tt.asc})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SortedChildKeys() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSortedChildKeysMtime(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC)
+	}
+	file := func(name string, modTime time.Time) MDFileInfo {
+		return MDFileInfo{Name: name, ModTime: modTime}
+	}
+	children := map[string]MDFileInfo{
+		"a": {Name: "a", IsDir: true, Children: map[string]MDFileInfo{
+			"old.md": file("old.md", day(1)),
+			"new.md": file("new.md", day(5)),
+		}},
+		"b": {Name: "b", IsDir: true, Children: map[string]MDFileInfo{
+			"mid.md": file("mid.md", day(3)),
+		}},
+		"c.md":  file("c.md", day(4)),
+		"empty": {Name: "empty", IsDir: true, Children: map[string]MDFileInfo{}},
+	}
+	tests := []struct {
+		name string
+		sort string
+		asc  bool
+		want []string
+	}{
+		{"newest child, ascending", SortMtime, true, []string{"empty", "b", "c.md", "a"}},
+		{"newest child, descending", SortMtime, false, []string{"a", "c.md", "b", "empty"}},
+		{"oldest child, ascending", SortMtimeOldest, true, []string{"empty", "a", "b", "c.md"}},
+		{"oldest child, descending", SortMtimeOldest, false, []string{"c.md", "b", "a", "empty"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SortedChildKeys(MDFileInfo{Children: children}, TocOptions{Sort: tt.sort, SortAsc: tt.asc})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SortedChildKeys() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSortedChildKeysDepth(t *testing.T) {
+	file := func(name string) MDFileInfo {
+		return MDFileInfo{Name: name}
+	}
+	dir := func(name string, children ...MDFileInfo) MDFileInfo {
+		md := MDFileInfo{Name: name, IsDir: true, Children: map[string]MDFileInfo{}}
+		for _, child := range children {
+			md.Children[child.Name] = child
+		}
+		return md
+	}
+	children := map[string]MDFileInfo{
+		"deep":    dir("deep", dir("a", dir("b", file("c.md")))),
+		"shallow": dir("shallow", file("x.md")),
+		"z.md":    file("z.md"),
+		"a.md":    file("a.md"),
+		"middle":  dir("middle", dir("m", file("y.md")), file("w.md")),
+	}
+	tests := []struct {
+		name string
+		asc  bool
+		want []string
+	}{
+		{"ascending", true, []string{"a.md", "z.md", "shallow", "middle", "deep"}},
+		{"descending", false, []string{"deep", "middle", "shallow", "z.md", "a.md"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SortedChildKeys(MDFileInfo{Children: children}, TocOptions{Sort: SortDepth, SortAsc: tt.asc})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SortedChildKeys() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	for name, want := range map[string]int{"z.md": 0, "shallow": 1, "middle": 2, "deep": 3} {
+		if got := SubtreeDepth(children[name]); got != want {
+			t.Errorf("SubtreeDepth(%s) = %d, want %d", name, got, want)
+		}
+	}
+}
+
+func TestSortedChildKeysNone(t *testing.T) {
+	children := map[string]MDFileInfo{
+		"b.md":      weighted("b.md", "1"),
+		"a.md":      weighted("a.md", "2"),
+		"c":         {Name: "c", IsDir: true},
+		"README.md": weighted("README.md", ""),
+	}
+	want := []string{"README.md", "a.md", "b.md", "c"}
+	for _, asc := range []bool{true, false} {
+		// The name order is kept whatever the direction, and the same on every run
+		for i := 0; i < 10; i++ {
+			if got := SortedChildKeys(MDFileInfo{Children: children}, TocOptions{Sort: SortNone, SortAsc: asc}); !reflect.DeepEqual(got, want) {
+				t.Fatalf("SortedChildKeys(asc=%v) = %q, want %q", asc, got, want)
+			}
+		}
+	}
+}
+
+func TestFileDate(t *testing.T) {
+	modTime := time.Date(2020, 5, 6, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		date string
+		want time.Time
+	}{
+		{"2024-01-02", time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{"2024-01-02T15:04:05Z", time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{"2024-01-02 15:04", time.Date(2024, 1, 2, 15, 4, 0, 0, time.UTC)},
+		{"2024/01/02", time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{"January 2, 2024", time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{"2 Jan 2024", time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{"", modTime},
+		{"soon", modTime},
+	}
+	for _, tt := range tests {
+		md := MDFileInfo{Frontmatter: map[string]string{"date": tt.date}, ModTime: modTime}
+		if got := FileDate(md); !got.Equal(tt.want) {
+			t.Errorf("FileDate(%q) = %v, want %v", tt.date, got, tt.want)
+		}
+	}
+}
+
+func TestSortedChildKeysDate(t *testing.T) {
+	dated := func(name, date string) MDFileInfo {
+		return MDFileInfo{Name: name, Frontmatter: map[string]string{"date": date}}
+	}
+	children := map[string]MDFileInfo{
+		"old.md": dated("old.md", "2023-05-01"),
+		"new.md": dated("new.md", "2024-03-01"),
+		"2023": {Name: "2023", IsDir: true, Children: map[string]MDFileInfo{
+			"a.md": dated("a.md", "2023-01-01"),
+			"b.md": dated("b.md", "2023-12-31"),
+		}},
+		"same.md": dated("same.md", "2024-03-01"),
+	}
+	tests := []struct {
+		name string
+		asc  bool
+		want []string
+	}{
+		{"newest first", true, []string{"new.md", "same.md", "2023", "old.md"}},
+		{"oldest first", false, []string{"old.md", "2023", "same.md", "new.md"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SortedChildKeys(MDFileInfo{Children: children}, TocOptions{Sort: SortDate, SortAsc: tt.asc})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SortedChildKeys() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadOrderFile(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"order.txt": "# Global order\n\nguides/advanced/scaling\n  intro.md\n./intro\nguides/\n",
+	})
+	got, err := LoadOrderFile(filepath.Join(dir, "order.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int{"guides/advanced/scaling": 0, "intro": 1, "guides": 2}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadOrderFile() = %v, want %v", got, want)
+	}
+	if _, err := LoadOrderFile(filepath.Join(dir, "missing.txt")); err == nil {
+		t.Error("LoadOrderFile(missing) = nil error, want an error")
+	}
+}
+
+func TestSortedChildKeysOrder(t *testing.T) {
+	md := listTree(t, writeTree(t, map[string]string{
+		"a.md":                       "# A\n",
+		"z.md":                       "# Z\n",
+		"intro.md":                   "# Intro\n",
+		"guides/start.md":            "# Start\n",
+		"guides/advanced/scaling.md": "# Scaling\n",
+	}), testListOptions())
+	order := map[string]int{"guides/advanced/scaling": 0, "intro": 1}
+	tests := []struct {
+		name string
+		node MDFileInfo
+		asc  bool
+		want []string
+	}{
+		{"root", md, true, []string{"guides", "intro.md", "a.md", "z.md"}},
+		{"root descending", md, false, []string{"guides", "intro.md", "z.md", "a.md"}},
+		{"directory", md.Children["guides"], true, []string{"advanced", "start.md"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := TocOptions{Sort: SortName, SortAsc: tt.asc, Order: order}
+			if got := SortedChildKeys(tt.node, opts); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SortedChildKeys() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if got := OrderRank(md.Children["z.md"], order); got != len(order) {
+		t.Errorf("OrderRank(z.md) = %d, want %d", got, len(order))
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// CreateTextTree generates a plain-text outline of the tree: the titles only, without any
+// Markdown or link syntax, indented by their depth under the title of the root. With opts.Wrap,
+// the long titles are wrapped, see WrapLine.
+//
+// Parameters:
+// - md: the MDFileInfo object representing the root directory.
+// - opts: the options used to render the TOC.
+//
+// Returns:
+// - string: the generated outline.
+func CreateTextTree(md MDFileInfo, opts TocOptions) string {
+	var sb strings.Builder
+	writeLine := func(indent, title string) {
+		for _, line := range WrapLine(indent, opts.Indent, title, opts.Wrap) {
+			sb.WriteString(line + "\n")
+		}
+	}
+	writeLine("", md.Title)
+	var walk func(node MDFileInfo)
+	walk = func(node MDFileInfo) {
+		for _, key := range SortedChildKeys(node, opts) {
+			child := node.Children[key]
+			writeLine(strings.Repeat(opts.Indent, child.Level), child.Title)
+			walk(child)
+		}
+	}
+	walk(md)
+	return sb.String()
+}
+
+// WrapLine wraps a title at word boundaries so that its lines, indentation included, fit in width
+// columns, counted in runes. The continuation lines are indented one more level than the first one,
+// so that they are not mistaken for the titles of the entries below. A word longer than the width
+// is left on its own line.
+//
+// Parameters:
+// - indent: the indentation of the line.
+// - hang: the indentation added to the continuation lines.
+// - title: the title.
+// - width: the number of columns, 0 or less not to wrap.
+//
+// Returns:
+// - []string: the lines, with their indentation.
+func WrapLine(indent, hang, title string, width int) []string {
+	words := strings.Fields(title)
+	if width <= 0 || len(words) == 0 {
+		return []string{indent + title}
+	}
+	var lines []string
+	line := indent + words[0]
+	for _, word := range words[1:] {
+		if utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) > width {
+			lines = append(lines, line)
+			line = indent + hang + word
+			continue
+		}
+		line += " " + word
+	}
+	return append(lines, line)
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCreateTextTree(t *testing.T) {
+	md := sampleDocs(t)
+	want := "Docs\n" +
+		"  guides\n" +
+		"    advanced\n" +
+		"      Scaling\n" +
+		"    Getting Started\n" +
+		"  Intro\n"
+	if got := CreateTextTree(md, testTocOptions()); got != want {
+		t.Errorf("CreateTextTree() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWrapLine(t *testing.T) {
+	tests := []struct {
+		name   string
+		indent string
+		title  string
+		width  int
+		want   []string
+	}{
+		{"no wrap", "  ", "a long title here", 0, []string{"  a long title here"}},
+		{"fits", "", "short title", 20, []string{"short title"}},
+		{"root", "", "one two three", 8, []string{"one two", "  three"}},
+		{"nested", "  ", "one two three four", 10, []string{"  one two", "    three", "    four"}},
+		{"long word", "", "a supercalifragilistic b", 10, []string{"a", "  supercalifragilistic", "  b"}},
+		{"runes", "", "żółw ślimak kot", 10, []string{"żółw", "  ślimak", "  kot"}},
+		{"empty", "  ", "", 10, []string{"  "}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := WrapLine(tt.indent, "  ", tt.title, tt.width); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("WrapLine(%q, %q, %d) = %q, want %q", tt.indent, tt.title, tt.width, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateTextTreeHangingIndent(t *testing.T) {
+	md := MDFileInfo{
+		Title: "Docs",
+		IsDir: true,
+		Children: map[string]MDFileInfo{
+			"guides": {
+				Name:  "guides",
+				Title: "guides",
+				IsDir: true,
+				Level: 1,
+				Children: map[string]MDFileInfo{
+					"start.md": {Name: "start.md", Title: "Getting started with the tool", Level: 2},
+				},
+			},
+		},
+	}
+	want := "Docs\n  guides\n    Getting started\n      with the tool\n"
+	if got := CreateTextTree(md, TocOptions{Indent: "  ", Wrap: 20}); got != want {
+		t.Errorf("CreateTextTree() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,496 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"html"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// TitleSource extracts a title from the lines of a Markdown file.
+// It returns the title and true if the source found one.
+type TitleSource func(lines []string) (string, bool)
+
+// TitleSources maps the names accepted by the `-title-strategy` flag to their TitleSource.
+var TitleSources = map[string]TitleSource{
+	"frontmatter": FrontmatterTitle,
+	"h1":          H1Title,
+	"heading":     HeadingTitle,
+	"setext":      SetextTitle,
+	"html":        HTMLTitle,
+	"first-line":  FirstLineTitle,
+}
+
+// Values of the `-multiple-h1` flag, how the title is picked from a file with several H1 headers.
+const (
+	MultipleH1First = "first"
+	MultipleH1Last  = "last"
+	MultipleH1Error = "error"
+)
+
+// DefaultTitleStrategy is the title strategy used when none is given: the first H1 header, then
+// the first header underlined with `===`, then the first HTML `<h1>` element.
+var DefaultTitleStrategy = []string{"h1", "setext", "html"}
+
+var (
+	h1Regex       = regexp.MustCompile(`^#\s+(.*)$`)
+	headingRegex  = regexp.MustCompile(`^#{1,6}\s+(.*)$`)
+	fenceRegex    = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})")
+	setextRegex   = regexp.MustCompile(`^=+\s*$`)
+	setextH2Regex = regexp.MustCompile(`^-+\s*$`)
+	listItemRegex = regexp.MustCompile(`^\s*(?:[-*+]|\d{1,9}[.)])(?:\s|$)`)
+	breakRegex    = regexp.MustCompile(`^ {0,3}(?:(?:- *){3,}|(?:\* *){3,}|(?:_ *){3,})$`)
+	htmlH1Regex   = regexp.MustCompile(`(?i)<h1(?:\s[^>]*)?>(.*?)</h1\s*>`)
+	htmlTagRegex  = regexp.MustCompile(`<[^>]*>`)
+)
+
+// ParseTitleStrategy parses a comma-separated list of title source names into the title sources
+// tried in order, see ResolveTitleFromLines.
+//
+// Parameters:
+// - value: the value of the `-title-strategy` flag, e.g. "frontmatter,h1,first-line".
+// - anyHeading: whether the `h1` source is replaced with `heading`, which accepts headers of any level.
+// - multipleH1: how the `h1` source picks the title of a file with several H1 headers, see the
+// MultipleH1 constants, MultipleH1Last for the last one and the first one otherwise.
+//
+// Returns:
+// - []TitleSource: the sources in order.
+// - error: an error if a name is not a known title source, or if anyHeading is combined with a
+// multipleH1 other than MultipleH1First, which only applies to the H1 headers.
+func ParseTitleStrategy(value string, anyHeading bool, multipleH1 string) ([]TitleSource, error) {
+	if anyHeading && multipleH1 != MultipleH1First {
+		return nil, fmt.Errorf("-multiple-h1 %s cannot be used with -any-heading, which titles the files with their first header of any level", multipleH1)
+	}
+	names := strings.Split(value, ",")
+	if strings.TrimSpace(value) == "" {
+		names = DefaultTitleStrategy
+	}
+	var strategy []TitleSource
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		source, ok := TitleSources[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown title source %q", name)
+		}
+		if name == "h1" {
+			if anyHeading {
+				source = HeadingTitle
+			} else if multipleH1 == MultipleH1Last {
+				source = LastH1Title
+			}
+		}
+		strategy = append(strategy, source)
+	}
+	return strategy, nil
+}
+
+// RegexTitleSource returns a TitleSource matching the lines against a regular expression, for the
+// nonstandard formats such as notebook exports. The first capture group of the first matching line
+// is the title.
+//
+// Parameters:
+// - pattern: the regular expression, with at least one capture group.
+//
+// Returns:
+// - TitleSource: the title source.
+// - error: an error if the pattern is invalid or has no capture group.
+func RegexTitleSource(pattern string) (TitleSource, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if re.NumSubexp() < 1 {
+		return nil, fmt.Errorf("%q has no capture group", pattern)
+	}
+	return func(lines []string) (string, bool) {
+		for _, line := range lines {
+			if match := re.FindStringSubmatch(line); match != nil {
+				return strings.TrimSpace(match[1]), true
+			}
+		}
+		return "", false
+	}, nil
+}
+
+// GetMDTitle retrieves the title of a Markdown file, the title of the file is the first H1 header.
+//
+// It takes a filePath string parameter, which represents the path of the Markdown file.
+// If there is no Markdown H1 header, the inner text of the first HTML `<h1>` element is used instead.
+// If no H1 header is found or an error occurs while opening the file, it returns an empty string.
+//
+// Parameters:
+// - filePath: the path of the Markdown file.
+//
+// Return type:
+// - string: the title of the Markdown file, or an empty string if no title is found or an error occurs.
+func GetMDTitle(filePath string) string {
+	strategy, _ := ParseTitleStrategy("", false, MultipleH1First)
+	return ResolveTitle(filePath, strategy)
+}
+
+// ResolveTitle retrieves the title of a Markdown file by trying each title source of the strategy
+// in order, the first source which finds a title wins.
+//
+// Parameters:
+// - filePath: the path of the Markdown file.
+// - strategy: the title sources to try, see ParseTitleStrategy.
+//
+// Returns:
+// - string: the title of the Markdown file, or an empty string if no title is found or an error occurs.
+func ResolveTitle(filePath string, strategy []TitleSource) string {
+	lines, err := ReadLines(filePath)
+	if err != nil {
+		return ""
+	}
+	return ResolveTitleFromLines(lines, strategy)
+}
+
+// ResolveTitleFromLines retrieves the title from the lines of a Markdown file, see ResolveTitle.
+//
+// Parameters:
+// - lines: the lines of the Markdown file.
+// - strategy: the title sources to try, see ParseTitleStrategy.
+//
+// Returns:
+// - string: the title of the Markdown file, or an empty string if no title is found.
+func ResolveTitleFromLines(lines []string, strategy []TitleSource) string {
+	for _, source := range strategy {
+		if title, ok := source(lines); ok {
+			return title
+		}
+	}
+	return ""
+}
+
+// ReadLines reads the lines of the given file.
+//
+// Parameters:
+// - filePath: the path of the file.
+//
+// Returns:
+// - []string: the lines of the file, without line endings.
+// - error: an error if the file could not be read.
+func ReadLines(filePath string) ([]string, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return SplitLines(content), nil
+}
+
+// SplitLines splits the content of a file into lines, without line endings.
+//
+// Parameters:
+// - content: the content of the file.
+//
+// Returns:
+// - []string: the lines of the content.
+func SplitLines(content []byte) []string {
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines
+}
+
+// SplitFrontmatter splits the lines of a Markdown file into its YAML frontmatter and its body.
+//
+// The frontmatter is the block between a `---` first line and the next `---` line.
+// If the file has no frontmatter, the returned frontmatter is nil and the body is all the lines.
+//
+// Parameters:
+// - lines: the lines of the Markdown file.
+//
+// Returns:
+// - []string: the lines of the frontmatter, without the `---` delimiters.
+// - []string: the lines of the body.
+func SplitFrontmatter(lines []string) ([]string, []string) {
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return nil, lines
+	}
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "---" {
+			return lines[1:i], lines[i+1:]
+		}
+	}
+	return nil, lines
+}
+
+// ParseFrontmatter returns the top-level `key: value` pairs of the YAML frontmatter.
+//
+// Only scalar values are supported, surrounding quotes are removed. Nested values, lists
+// and comments are ignored.
+//
+// Parameters:
+// - lines: the lines of the Markdown file.
+//
+// Returns:
+// - map[string]string: the frontmatter values, empty if the file has no frontmatter.
+func ParseFrontmatter(lines []string) map[string]string {
+	frontmatter, _ := SplitFrontmatter(lines)
+	return ParseYAMLValues(frontmatter)
+}
+
+// ParseYAMLValues returns the top-level `key: value` pairs of YAML lines, with the same
+// limitations as ParseFrontmatter.
+//
+// Parameters:
+// - lines: the YAML lines.
+//
+// Returns:
+// - map[string]string: the values.
+func ParseYAMLValues(lines []string) map[string]string {
+	values := make(map[string]string)
+	for _, line := range lines {
+		if line == "" || line[0] == ' ' || line[0] == '\t' || line[0] == '#' || line[0] == '-' {
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		values[strings.TrimSpace(key)] = value
+	}
+	return values
+}
+
+// SidecarTitle returns the `title` of the sidecar metadata file of a Markdown file, which is named
+// after the file with the extension appended, e.g. `page.md.meta` for `.meta`, or replacing its
+// `.md` extension, e.g. `page.yaml` for `.yaml`. The sidecar holds YAML `key: value` lines.
+//
+// Parameters:
+// - filePath: the path of the Markdown file.
+// - ext: the extension of the sidecar, with its leading dot.
+//
+// Returns:
+// - string: the title, or an empty string if there is no sidecar or it has no title.
+func SidecarTitle(filePath, ext string) string {
+	for _, sidecar := range []string{filePath + ext, strings.TrimSuffix(filePath, ".md") + ext} {
+		if lines, err := ReadLines(sidecar); err == nil {
+			return ParseYAMLValues(lines)["title"]
+		}
+	}
+	return ""
+}
+
+// IsDraft reports whether the frontmatter marks the file as a draft, with `draft: true`
+// or `published: false`.
+//
+// Parameters:
+// - frontmatter: the frontmatter values returned by ParseFrontmatter.
+//
+// Returns:
+// - bool: true if the file is a draft.
+func IsDraft(frontmatter map[string]string) bool {
+	if draft, err := strconv.ParseBool(frontmatter["draft"]); err == nil && draft {
+		return true
+	}
+	if published, err := strconv.ParseBool(frontmatter["published"]); err == nil && !published {
+		return true
+	}
+	return false
+}
+
+// FrontmatterTitle returns the `title` field of the YAML frontmatter.
+func FrontmatterTitle(lines []string) (string, bool) {
+	title, ok := ParseFrontmatter(lines)["title"]
+	return title, ok && title != ""
+}
+
+// ProseLines returns the lines of the body with the lines of fenced code blocks blanked out,
+// so that a `# comment` in a code sample is not taken for a header.
+//
+// Parameters:
+// - lines: the lines of the Markdown file.
+//
+// Returns:
+// - []string: the lines of the body, with as many lines as the body.
+func ProseLines(lines []string) []string {
+	_, body := SplitFrontmatter(lines)
+	prose := make([]string, len(body))
+	fence := ""
+	for i, line := range body {
+		match := fenceRegex.FindStringSubmatch(line)
+		switch {
+		case fence == "" && match != nil:
+			fence = match[1]
+		case fence != "":
+			// A fence is closed by the same character, at least as many times, and nothing else
+			if match != nil && match[1][0] == fence[0] && len(match[1]) >= len(fence) && strings.TrimSpace(line[len(match[0]):]) == "" {
+				fence = ""
+			}
+		default:
+			prose[i] = line
+		}
+	}
+	return prose
+}
+
+// H1Title returns the text of the first H1 header, e.g. `# Title`.
+func H1Title(lines []string) (string, bool) {
+	for _, line := range ProseLines(lines) {
+		if match := h1Regex.FindStringSubmatch(line); match != nil {
+			return match[1], true
+		}
+	}
+	return "", false
+}
+
+// LastH1Title returns the text of the last H1 header, for the files which wrongly have several.
+func LastH1Title(lines []string) (string, bool) {
+	titles := H1Titles(lines)
+	if len(titles) == 0 {
+		return "", false
+	}
+	return titles[len(titles)-1], true
+}
+
+// H1Titles returns the texts of all the H1 headers, e.g. `# Title`, in order.
+func H1Titles(lines []string) []string {
+	var titles []string
+	for _, line := range ProseLines(lines) {
+		if match := h1Regex.FindStringSubmatch(line); match != nil {
+			titles = append(titles, match[1])
+		}
+	}
+	return titles
+}
+
+// HeadingTitle returns the text of the first ATX header of any level, from `# Title` to `###### Title`.
+func HeadingTitle(lines []string) (string, bool) {
+	for _, line := range ProseLines(lines) {
+		if match := headingRegex.FindStringSubmatch(line); match != nil {
+			return match[1], true
+		}
+	}
+	return "", false
+}
+
+// CountHeadings returns the number of ATX headers, from `# Title` to `###### Title`, and Setext H1
+// and H2 headers of the lines, outside of the fenced code blocks. A `---` line only underlines
+// the text line right above it, otherwise it is a thematic break.
+func CountHeadings(lines []string) int {
+	count := 0
+	prose := ProseLines(lines)
+	for i, line := range prose {
+		if headingRegex.MatchString(line) || SetextLevel(prose, i) > 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// SetextLevel returns the level of the Setext header underlined by the line i of the body, 1 for
+// `===` and 2 for `---`, or 0 if the line does not underline a header. Only the text of a paragraph
+// can be underlined: after a blank line, a header, a list item, a table row or a blockquote, `---` is
+// a thematic break and `===` is plain text.
+//
+// Parameters:
+// - prose: the lines of the body, as returned by ProseLines.
+// - i: the index of the line in prose.
+//
+// Returns:
+// - int: the level of the header, or 0.
+func SetextLevel(prose []string, i int) int {
+	level := 0
+	switch {
+	case i == 0:
+		return 0
+	case setextRegex.MatchString(prose[i]):
+		level = 1
+	case setextH2Regex.MatchString(prose[i]):
+		level = 2
+	default:
+		return 0
+	}
+	if !isParagraphLine(prose[i-1]) {
+		return 0
+	}
+	return level
+}
+
+// isParagraphLine reports whether a line of the body is the text of a paragraph, rather than blank
+// or the start of another block.
+func isParagraphLine(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return trimmed != "" && !headingRegex.MatchString(line) && !listItemRegex.MatchString(line) &&
+		!strings.HasPrefix(trimmed, ">") && !strings.HasPrefix(trimmed, "|") &&
+		!setextRegex.MatchString(line) && !setextH2Regex.MatchString(line) && !breakRegex.MatchString(line)
+}
+
+// CountWords returns the number of words of the body of a Markdown file, outside of its frontmatter
+// and of the fenced code blocks. The Markdown markers, e.g. `#` or `-`, are not counted.
+func CountWords(lines []string) int {
+	count := 0
+	for _, line := range ProseLines(lines) {
+		for _, word := range strings.Fields(line) {
+			if strings.IndexFunc(word, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) >= 0 {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// SetextTitle returns the text of the first Setext H1 header, a line underlined with `=`.
+func SetextTitle(lines []string) (string, bool) {
+	prose := ProseLines(lines)
+	for i := 1; i < len(prose); i++ {
+		if SetextLevel(prose, i) == 1 {
+			return strings.TrimSpace(prose[i-1]), true
+		}
+	}
+	return "", false
+}
+
+// HTMLTitle returns the inner text of the first HTML `<h1>` element.
+func HTMLTitle(lines []string) (string, bool) {
+	for _, line := range ProseLines(lines) {
+		if title := GetHTMLH1(line); title != "" {
+			return title, true
+		}
+	}
+	return "", false
+}
+
+// FirstLineTitle returns the first non-blank line of the body, without leading `#` markers.
+func FirstLineTitle(lines []string) (string, bool) {
+	_, body := SplitFrontmatter(lines)
+	for _, line := range body {
+		line = strings.TrimSpace(strings.TrimLeft(line, "#"))
+		if line != "" {
+			return line, true
+		}
+	}
+	return "", false
+}
+
+// GetHTMLH1 returns the inner text of the first HTML `<h1>` element on the given line.
+//
+// The element may have attributes, nested tags are stripped and HTML entities are unescaped.
+//
+// Parameters:
+// - line: a line of a Markdown file.
+//
+// Returns:
+// - string: the text of the heading, or an empty string if the line has no `<h1>` element.
+func GetHTMLH1(line string) string {
+	match := htmlH1Regex.FindStringSubmatch(line)
+	if match == nil {
+		return ""
+	}
+	return strings.TrimSpace(html.UnescapeString(htmlTagRegex.ReplaceAllString(match[1], "")))
+}
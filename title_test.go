@@ -0,0 +1,334 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTitleStrategy(t *testing.T) {
+	lines := []string{"---", "title: Front", "---", "## Sub", "# First", "# Last", "<h1>Html</h1>"}
+	tests := []struct {
+		value      string
+		anyHeading bool
+		want       string
+		wantErr    bool
+	}{
+		{"", false, "First", false},
+		{"h1,html", false, "First", false},
+		{"frontmatter,h1", false, "Front", false},
+		{"h1", true, "Sub", false},
+		{"html", false, "Html", false},
+		{" html , h1 ", false, "Html", false},
+		{"setext", false, "", false},
+		{"h2", false, "", true},
+	}
+	for _, tt := range tests {
+		strategy, err := ParseTitleStrategy(tt.value, tt.anyHeading, MultipleH1First)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("ParseTitleStrategy(%q) error = %v, want error %v", tt.value, err, tt.wantErr)
+		}
+		if got := ResolveTitleFromLines(lines, strategy); got != tt.want {
+			t.Errorf("ParseTitleStrategy(%q, %v) resolves %q, want %q", tt.value, tt.anyHeading, got, tt.want)
+		}
+	}
+}
+
+func TestParseTitleStrategyMultipleH1(t *testing.T) {
+	lines := []string{"# First", "```", "# Fenced", "```", "# Last"}
+	tests := []struct {
+		multipleH1 string
+		value      string
+		want       string
+	}{
+		{MultipleH1First, "h1", "First"},
+		{MultipleH1Error, "h1", "First"},
+		{MultipleH1Last, "h1", "Last"},
+		{MultipleH1Last, "frontmatter,h1", "Last"},
+	}
+	for _, tt := range tests {
+		strategy, err := ParseTitleStrategy(tt.value, false, tt.multipleH1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := ResolveTitleFromLines(lines, strategy); got != tt.want {
+			t.Errorf("ParseTitleStrategy(%q, %q) resolves %q, want %q", tt.value, tt.multipleH1, got, tt.want)
+		}
+	}
+	// The H1 headers are not looked for with anyHeading
+	for _, multipleH1 := range []string{MultipleH1Last, MultipleH1Error} {
+		if _, err := ParseTitleStrategy("h1", true, multipleH1); err == nil {
+			t.Errorf("ParseTitleStrategy(any heading, %q) error = nil, want an error", multipleH1)
+		}
+	}
+	// The choice is not global, the default strategy still picks the first H1
+	if got, _ := TitleSources["h1"](lines); got != "First" {
+		t.Errorf(`TitleSources["h1"] = %q, want "First"`, got)
+	}
+}
+
+func TestRegexTitleSource(t *testing.T) {
+	tests := []struct {
+		pattern string
+		lines   []string
+		want    string
+		found   bool
+		wantErr bool
+	}{
+		{`^<!-- title: (.*) -->$`, []string{"text", "<!-- title: Notebook -->"}, "Notebook", true, false},
+		{`^<!-- title: (.*) -->$`, []string{"# Heading"}, "", false, false},
+		{`^Title:(.*)$`, []string{"Title:  Padded  "}, "Padded", true, false},
+		{`^title$`, nil, "", false, true},
+		{`(`, nil, "", false, true},
+	}
+	for _, tt := range tests {
+		source, err := RegexTitleSource(tt.pattern)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("RegexTitleSource(%q) error = %v, want error %v", tt.pattern, err, tt.wantErr)
+		}
+		if err != nil {
+			continue
+		}
+		if got, found := source(tt.lines); got != tt.want || found != tt.found {
+			t.Errorf("RegexTitleSource(%q)(%q) = %q, %v, want %q, %v", tt.pattern, tt.lines, got, found, tt.want, tt.found)
+		}
+	}
+	if _, ok := TitleSources["regex"]; ok {
+		t.Error("RegexTitleSource registered a global title source")
+	}
+}
+
+func TestGetHTMLH1(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{
+		{"<h1>Title</h1>", "Title"},
+		{`<h1 align="center">Centered</h1>`, "Centered"},
+		{"<H1>Upper</H1 >", "Upper"},
+		{"<h1><img src=\"logo.png\"> Project <em>X</em></h1>", "Project X"},
+		{"<h1>Fish &amp; Chips</h1>", "Fish & Chips"},
+		{"<h2>Not a title</h2>", ""},
+		{"<h1>Unclosed", ""},
+		{"<h10>No</h10>", ""},
+	}
+	for _, tt := range tests {
+		if got := GetHTMLH1(tt.line); got != tt.want {
+			t.Errorf("GetHTMLH1(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestTitleSources(t *testing.T) {
+	tests := []struct {
+		source string
+		lines  []string
+		want   string
+		found  bool
+	}{
+		{"frontmatter", []string{"---", "title: From YAML", "---", "# H1"}, "From YAML", true},
+		{"frontmatter", []string{"---", `title: "Quoted: yes"`, "---"}, "Quoted: yes", true},
+		{"frontmatter", []string{"---", "title: \"\"", "---"}, "", false},
+		{"frontmatter", []string{"# H1"}, "", false},
+		{"h1", []string{"intro", "# First", "# Second"}, "First", true},
+		{"h1", []string{"```", "# comment", "```", "# Real"}, "Real", true},
+		{"h1", []string{"## Sub"}, "", false},
+		{"heading", []string{"text", "### Deep"}, "Deep", true},
+		{"setext", []string{"", "Title", "====="}, "Title", true},
+		{"setext", []string{"", "====="}, "", false},
+		{"setext", []string{"- item", "====="}, "", false},
+		{"html", []string{"<p align=\"center\">", "<h1>Logo Title</h1>", "</p>"}, "Logo Title", true},
+		{"html", []string{"```html", "<h1>Sample</h1>", "```"}, "", false},
+		{"html", []string{"text"}, "", false},
+		{"first-line", []string{"---", "a: b", "---", "", "## First line"}, "First line", true},
+		{"first-line", []string{"", "  "}, "", false},
+	}
+	for _, tt := range tests {
+		got, found := TitleSources[tt.source](tt.lines)
+		if got != tt.want || found != tt.found {
+			t.Errorf("%s(%q) = %q, %v, want %q, %v", tt.source, tt.lines, got, found, tt.want, tt.found)
+		}
+	}
+}
+
+func TestResolveTitle(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"markdown.md": "<h1>Html</h1>\n# Markdown\n",
+		"html.md":     "<h1>Html</h1>\n## Sub\n",
+		"yaml.md":     "---\ntitle: From YAML\n---\n# H1\n",
+		"none.md":     "text\n",
+		"setext.md":   "Setext Title\n============\n\ntext\n",
+		"both.md":     "<h1>Html</h1>\n\nSetext\n======\n",
+	})
+	tests := []struct {
+		file     string
+		strategy string
+		want     string
+	}{
+		// The default strategy falls back to the HTML title when there is no H1 header
+		{"markdown.md", "", "Markdown"},
+		{"html.md", "", "Html"},
+		// A file whose only heading is underlined is titled by default, before the HTML title
+		{"setext.md", "", "Setext Title"},
+		{"both.md", "", "Setext"},
+		{"yaml.md", "", "H1"},
+		{"yaml.md", "frontmatter,h1", "From YAML"},
+		{"none.md", "", ""},
+		{"none.md", "h1,first-line", "text"},
+		{"missing.md", "", ""},
+	}
+	for _, tt := range tests {
+		strategy, err := ParseTitleStrategy(tt.strategy, false, MultipleH1First)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := ResolveTitle(filepath.Join(dir, tt.file), strategy); got != tt.want {
+			t.Errorf("ResolveTitle(%s, %q) = %q, want %q", tt.file, tt.strategy, got, tt.want)
+		}
+	}
+}
+
+func TestIsDraft(t *testing.T) {
+	tests := []struct {
+		frontmatter map[string]string
+		want        bool
+	}{
+		{nil, false},
+		{map[string]string{"draft": "true"}, true},
+		{map[string]string{"draft": "false"}, false},
+		{map[string]string{"draft": "yes"}, false},
+		{map[string]string{"published": "false"}, true},
+		{map[string]string{"published": "true"}, false},
+		{map[string]string{"draft": "false", "published": "false"}, true},
+	}
+	for _, tt := range tests {
+		if got := IsDraft(tt.frontmatter); got != tt.want {
+			t.Errorf("IsDraft(%v) = %v, want %v", tt.frontmatter, got, tt.want)
+		}
+	}
+}
+
+func TestAnyHeadingTitle(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		any   string
+		h1    string
+	}{
+		{"h2 only", []string{"intro text", "## Setup", "# Later"}, "Setup", "Later"},
+		{"h1 first", []string{"# Title", "## Section"}, "Title", "Title"},
+		{"h6", []string{"###### Tiny"}, "Tiny", ""},
+		{"fenced", []string{"```", "## not", "```", "### Real"}, "Real", ""},
+		{"not a heading", []string{"#hashtag", "####### seven"}, "", ""},
+	}
+	anyHeading, _ := ParseTitleStrategy("h1", true, MultipleH1First)
+	h1Only, _ := ParseTitleStrategy("h1", false, MultipleH1First)
+	for _, tt := range tests {
+		if got := ResolveTitleFromLines(tt.lines, anyHeading); got != tt.any {
+			t.Errorf("%s: any heading title = %q, want %q", tt.name, got, tt.any)
+		}
+		if got := ResolveTitleFromLines(tt.lines, h1Only); got != tt.h1 {
+			t.Errorf("%s: h1 title = %q, want %q", tt.name, got, tt.h1)
+		}
+	}
+}
+
+func TestSidecarTitle(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"appended.md":      "# Appended\n",
+		"appended.md.meta": "title: From Meta\n",
+		"replaced.md":      "# Replaced\n",
+		"replaced.meta":    "author: me\ntitle: \"Quoted: Title\"\n",
+		"untitled.md":      "# Untitled\n",
+		"untitled.md.meta": "author: me\n",
+		"plain.md":         "# Plain\n",
+	})
+	tests := []struct {
+		file string
+		want string
+	}{
+		{"appended.md", "From Meta"},
+		{"replaced.md", "Quoted: Title"},
+		{"untitled.md", ""},
+		{"plain.md", ""},
+	}
+	for _, tt := range tests {
+		if got := SidecarTitle(filepath.Join(dir, tt.file), ".meta"); got != tt.want {
+			t.Errorf("SidecarTitle(%q) = %q, want %q", tt.file, got, tt.want)
+		}
+	}
+	opts := testListOptions()
+	opts.SidecarExt = ".meta"
+	md := listTree(t, dir, opts)
+	got := titlesInOrder(md, testTocOptions())
+	want := []string{"From Meta", "Plain", "Quoted: Title", "Untitled"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("titles = %q, want %q", got, want)
+	}
+}
+
+func TestCountHeadings(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    int
+	}{
+		{"none", "Just text.\n", 0},
+		{"atx", "# One\n\n## Two\n\n###### Six\n####### Seven\n", 3},
+		{"setext", "Title\n=====\n\nPart\n----\n", 2},
+		{"thematic break", "Text\n\n---\n\nMore\n", 0},
+		{"break after a list", "- one\n- two\n---\n", 0},
+		{"break after a table", "| a | b |\n| - | - |\n| 1 | 2 |\n---\n", 0},
+		{"break after a blockquote", "> quote\n---\n", 0},
+		{"text after a list", "1. one\n===\n", 0},
+		{"break after a heading", "# One\n---\n", 1},
+		{"frontmatter", "---\ntitle: x\n---\n# One\n", 1},
+		{"code block", "# One\n\n```sh\n# comment\n```\n", 1},
+		{"no space", "#hashtag\n", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CountHeadings(SplitLines([]byte(tt.content))); got != tt.want {
+				t.Errorf("CountHeadings() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShowHeadingCount(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"one.md":  "# One\n",
+		"many.md": "# Many\n\n## A\n\n## B\n",
+		"none.md": "Text\n",
+	})
+	opts := testTocOptions()
+	opts.ShowHeadingCount = true
+	got := CreateTocTree(listTree(t, dir, testListOptions()), opts)
+	for _, want := range []string{"[Many](./many.md) (3 headings)", "(./none.md) (0 headings)", "[One](./one.md) (1 heading)"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("CreateTocTree() does not contain %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestCountWords(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    int
+	}{
+		{"empty", "", 0},
+		{"prose", "# Title\n\nTwo words.\n", 3},
+		{"markers", "- one\n- two\n\n---\n> three\n", 3},
+		{"frontmatter", "---\ntitle: Not counted\n---\nCounted\n", 1},
+		{"code block", "Before\n\n```\nnot counted here\n```\nAfter\n", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CountWords(SplitLines([]byte(tt.content))); got != tt.want {
+				t.Errorf("CountWords() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,276 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// NumberSections returns a copy of the tree where the title of every descendant is prefixed
+// with its hierarchical section number, e.g. `1`, `1.1`, `1.1.1`.
+//
+// The numbers follow the rendering order given by the options, so they restart at 1
+// for the children of every node.
+//
+// Parameters:
+// - md: the MDFileInfo object representing the file or directory.
+// - number: the section number of md, an empty string for the root.
+// - opts: the options used to render the TOC.
+//
+// Returns:
+// - MDFileInfo: the numbered copy of md.
+func NumberSections(md MDFileInfo, number string, opts TocOptions) MDFileInfo {
+	if number != "" {
+		md.Title = number + " " + md.Title
+	}
+	if md.Children == nil {
+		return md
+	}
+	children := make(map[string]MDFileInfo, len(md.Children))
+	for i, key := range SortedChildKeys(md, opts) {
+		childNumber := strconv.Itoa(i + 1)
+		if number != "" {
+			childNumber = number + "." + childNumber
+		}
+		children[key] = NumberSections(md.Children[key], childNumber, opts)
+	}
+	md.Children = children
+	return md
+}
+
+// LimitDepth returns a copy of the tree without the nodes deeper than maxDepth.
+// The traversal stops at maxDepth, so it is safe to use on arbitrarily deep trees.
+//
+// Parameters:
+// - md: the MDFileInfo object representing the file or directory.
+// - maxDepth: the level of the deepest nodes to keep.
+//
+// Returns:
+// - MDFileInfo: the truncated copy of md.
+func LimitDepth(md MDFileInfo, maxDepth int) MDFileInfo {
+	if md.Children == nil {
+		return md
+	}
+	children := make(map[string]MDFileInfo)
+	if md.Level < maxDepth {
+		for key, child := range md.Children {
+			children[key] = LimitDepth(child, maxDepth)
+		}
+	}
+	md.Children = children
+	return md
+}
+
+// DirsOnly returns a copy of the tree without its files, keeping the directory hierarchy.
+//
+// Parameters:
+// - md: the MDFileInfo object representing the root directory.
+//
+// Returns:
+// - MDFileInfo: the copy of md with directories only.
+func DirsOnly(md MDFileInfo) MDFileInfo {
+	children := make(map[string]MDFileInfo)
+	for key, child := range md.Children {
+		if child.IsDir {
+			children[key] = DirsOnly(child)
+		}
+	}
+	md.Children = children
+	return md
+}
+
+// HumanizeDirs returns a copy of the tree where the directories titled after their name, i.e.
+// without a title file or a README title, are titled with HumanizeName instead. The paths are kept.
+//
+// Parameters:
+// - md: the MDFileInfo object representing the root directory.
+// - acronyms: the words kept in upper case, see HumanizeName.
+//
+// Returns:
+// - MDFileInfo: the humanized copy of md.
+func HumanizeDirs(md MDFileInfo, acronyms []string) MDFileInfo {
+	if md.Level > 0 && md.Title == md.Name {
+		md.Title = HumanizeName(md.Name, acronyms)
+	}
+	children := make(map[string]MDFileInfo, len(md.Children))
+	for key, child := range md.Children {
+		if child.IsDir {
+			child = HumanizeDirs(child, acronyms)
+		}
+		children[key] = child
+	}
+	md.Children = children
+	return md
+}
+
+// HumanizeName turns a file or directory name into a title, e.g. `Api Reference` for `api-reference`:
+// dashes and underscores become spaces and every word starts with an upper-case letter.
+// The words matching one of the acronyms, regardless of case, are spelled like the acronym,
+// e.g. `API Reference` with the API acronym.
+//
+// Parameters:
+// - name: the name, without extension.
+// - acronyms: the words kept in upper case, e.g. API, URL or HTTP.
+//
+// Returns:
+// - string: the title.
+func HumanizeName(name string, acronyms []string) string {
+	words := splitNameWords(name)
+	for i, word := range words {
+		if acronym, ok := matchAcronym(word, acronyms); ok {
+			words[i] = acronym
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(word)
+		words[i] = string(unicode.ToUpper(r)) + word[size:]
+	}
+	return strings.Join(words, " ")
+}
+
+// splitNameWords splits a file or directory name into words on dashes, underscores and spaces.
+func splitNameWords(name string) []string {
+	return strings.FieldsFunc(name, func(r rune) bool {
+		return r == '-' || r == '_' || unicode.IsSpace(r)
+	})
+}
+
+// matchAcronym returns the acronym equal to the word regardless of case, if any.
+func matchAcronym(word string, acronyms []string) (string, bool) {
+	for _, acronym := range acronyms {
+		if strings.EqualFold(word, acronym) {
+			return acronym, true
+		}
+	}
+	return "", false
+}
+
+// Title cases supported by the `-title-case` flag.
+const (
+	TitleCaseNone  = "none"
+	TitleCaseLower = "lower"
+	TitleCaseUpper = "upper"
+	TitleCaseTitle = "title"
+)
+
+// CaseTitles returns a copy of the tree where every title, the root's included, is converted
+// with ConvertCase. The paths are kept.
+//
+// Parameters:
+// - md: the MDFileInfo object representing the root directory.
+// - mode: one of the TitleCase constants.
+//
+// Returns:
+// - MDFileInfo: the copy of md with converted titles.
+func CaseTitles(md MDFileInfo, mode string) MDFileInfo {
+	md.Title = ConvertCase(md.Title, mode)
+	if md.Children == nil {
+		return md
+	}
+	children := make(map[string]MDFileInfo, len(md.Children))
+	for key, child := range md.Children {
+		children[key] = CaseTitles(child, mode)
+	}
+	md.Children = children
+	return md
+}
+
+// PrefixIcons returns a copy of the tree where the title of every file, and of every directory
+// with an _index.md, is prefixed with the value of the first of the given frontmatter fields it
+// has, e.g. `🚀 Getting Started` for `icon: 🚀`. The paths are kept.
+//
+// Parameters:
+// - md: the MDFileInfo object representing the root directory.
+// - keys: the frontmatter fields tried in order.
+//
+// Returns:
+// - MDFileInfo: the copy of md with prefixed titles.
+func PrefixIcons(md MDFileInfo, keys []string) MDFileInfo {
+	if md.Level > 0 {
+		for _, key := range keys {
+			if icon := md.Frontmatter[key]; icon != "" {
+				md.Title = icon + " " + md.Title
+				break
+			}
+		}
+	}
+	if md.Children == nil {
+		return md
+	}
+	children := make(map[string]MDFileInfo, len(md.Children))
+	for key, child := range md.Children {
+		children[key] = PrefixIcons(child, keys)
+	}
+	md.Children = children
+	return md
+}
+
+// ConvertCase converts a title to lower case, upper case, or title case where every word
+// starts with a title-case letter followed by lower-case letters. Any other mode keeps it as is.
+//
+// Parameters:
+// - title: the title.
+// - mode: one of the TitleCase constants.
+//
+// Returns:
+// - string: the converted title.
+func ConvertCase(title, mode string) string {
+	switch mode {
+	case TitleCaseLower:
+		return strings.ToLower(title)
+	case TitleCaseUpper:
+		return strings.ToUpper(title)
+	case TitleCaseTitle:
+		runes := []rune(strings.ToLower(title))
+		for i, r := range runes {
+			// A word starts after anything but a letter, a digit or an apostrophe, e.g. `(Note)` or `Don't`
+			if i == 0 || !(unicode.IsLetter(runes[i-1]) || unicode.IsDigit(runes[i-1]) || runes[i-1] == '\'' || runes[i-1] == '’') {
+				runes[i] = unicode.ToTitle(r)
+			}
+		}
+		return string(runes)
+	}
+	return title
+}
+
+// Rebase returns a copy of the subtree of a directory where the directory is the root: the levels
+// are shifted so its level is 0 and the paths are made relative to it, e.g. `./start.md` for
+// `./guides/start.md`.
+//
+// Parameters:
+// - md: the MDFileInfo object representing the directory.
+// - relDir: the slash-separated path of the directory relative to the root directory.
+//
+// Returns:
+// - MDFileInfo: the rebased copy of md.
+func Rebase(md MDFileInfo, relDir string) MDFileInfo {
+	return rebase(md, md.Level, relDir)
+}
+
+func rebase(md MDFileInfo, level int, relDir string) MDFileInfo {
+	md.Level -= level
+	md.Path = rebasePath(md.Path, relDir)
+	md.LinkPath = rebasePath(md.LinkPath, relDir)
+	md.IndexPath = rebasePath(md.IndexPath, relDir)
+	if md.Children == nil {
+		return md
+	}
+	children := make(map[string]MDFileInfo, len(md.Children))
+	for key, child := range md.Children {
+		children[key] = rebase(child, level, relDir)
+	}
+	md.Children = children
+	return md
+}
+
+// rebasePath makes an escaped path relative to the root directory relative to relDir instead.
+func rebasePath(escapedPath, relDir string) string {
+	if escapedPath == "" || relDir == "." {
+		return escapedPath
+	}
+	rel := RelPath(MDFileInfo{Path: escapedPath})
+	if rel == relDir {
+		return "."
+	}
+	return EscapePath("./" + strings.TrimPrefix(rel, relDir+"/"))
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// titlesInOrder returns the titles of the descendants of md in rendering order, depth first.
+func titlesInOrder(md MDFileInfo, opts TocOptions) []string {
+	var titles []string
+	for _, key := range SortedChildKeys(md, opts) {
+		child := md.Children[key]
+		titles = append(titles, child.Title)
+		titles = append(titles, titlesInOrder(child, opts)...)
+	}
+	return titles
+}
+
+func TestNumberSections(t *testing.T) {
+	md := sampleDocs(t)
+	tests := []struct {
+		name string
+		asc  bool
+		want []string
+	}{
+		{"ascending", true, []string{"1 guides", "1.1 advanced", "1.1.1 Scaling", "1.2 Getting Started", "2 Intro"}},
+		{"descending", false, []string{"1 Intro", "2 guides", "2.1 Getting Started", "2.2 advanced", "2.2.1 Scaling"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := testTocOptions()
+			opts.SortAsc = tt.asc
+			numbered := NumberSections(md, "", opts)
+			if numbered.Title != "Docs" {
+				t.Errorf("root title = %q, want Docs", numbered.Title)
+			}
+			if got := titlesInOrder(numbered, opts); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("titles = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if got := titlesInOrder(md, testTocOptions()); got[0] != "guides" {
+		t.Errorf("NumberSections() changed the original tree: %q", got)
+	}
+}
+
+func TestDirsOnly(t *testing.T) {
+	md := sampleDocs(t)
+	dirs := DirsOnly(md)
+	if got, want := titlesInOrder(dirs, testTocOptions()), []string{"guides", "advanced"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("titles = %q, want %q", got, want)
+	}
+	want := "# Docs\n\n## guides\n\n- advanced\n"
+	if got := CreateTocTree(dirs, testTocOptions()); got != want {
+		t.Errorf("CreateTocTree() =\n%q\nwant\n%q", got, want)
+	}
+	if len(md.Children) != 2 {
+		t.Error("DirsOnly() changed the original tree")
+	}
+}
+
+func TestHumanizeDirs(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"api-reference/getting_started/a.md": "# A\n",
+		"custom/.title":                      "My_Custom-Title\n",
+		"custom/b.md":                        "# B\n",
+		"plain-file.md":                      "# Plain File\n",
+	})
+	md := HumanizeDirs(listTree(t, dir, testListOptions()), nil)
+	tests := []struct {
+		node MDFileInfo
+		want string
+	}{
+		{md.Children["api-reference"], "Api Reference"},
+		{md.Children["api-reference"].Children["getting_started"], "Getting Started"},
+		{md.Children["custom"], "My_Custom-Title"},
+		{md.Children["plain-file.md"], "Plain File"},
+	}
+	for _, tt := range tests {
+		if tt.node.Title != tt.want {
+			t.Errorf("%s title = %q, want %q", tt.node.Name, tt.node.Title, tt.want)
+		}
+	}
+	if got, want := RelPath(md.Children["api-reference"].Children["getting_started"].Children["a.md"]), "api-reference/getting_started/a.md"; got != want {
+		t.Errorf("path = %q, want %q", got, want)
+	}
+}
+
+func TestHumanizeNameAcronyms(t *testing.T) {
+	acronyms := []string{"API", "URL", "HTTP", "iOS"}
+	tests := []struct {
+		name     string
+		acronyms []string
+		want     string
+	}{
+		{"api-guide", nil, "Api Guide"},
+		{"api-guide", acronyms, "API Guide"},
+		{"http_url-parsing", acronyms, "HTTP URL Parsing"},
+		{"IOS-apps", acronyms, "iOS Apps"},
+		{"rapid-api", acronyms, "Rapid API"},
+		{"apis", acronyms, "Apis"},
+	}
+	for _, tt := range tests {
+		if got := HumanizeName(tt.name, tt.acronyms); got != tt.want {
+			t.Errorf("HumanizeName(%q, %q) = %q, want %q", tt.name, tt.acronyms, got, tt.want)
+		}
+	}
+	if got, want := TitleFromFileName("api-guide.md", acronyms), "API guide"; got != want {
+		t.Errorf("TitleFromFileName() = %q, want %q", got, want)
+	}
+	if got, want := TitleFromFileName("ios-url-notes.md", acronyms), "iOS URL notes"; got != want {
+		t.Errorf("TitleFromFileName() = %q, want %q", got, want)
+	}
+}
+
+func TestConvertCase(t *testing.T) {
+	tests := []struct {
+		title string
+		mode  string
+		want  string
+	}{
+		{"Getting STARTED", TitleCaseNone, "Getting STARTED"},
+		{"Getting STARTED", "unknown", "Getting STARTED"},
+		{"Getting STARTED", TitleCaseLower, "getting started"},
+		{"Getting started", TitleCaseUpper, "GETTING STARTED"},
+		{"getting STARTED", TitleCaseTitle, "Getting Started"},
+		{"don't panic (note)", TitleCaseTitle, "Don't Panic (Note)"},
+		{"it’s 2nd-hand", TitleCaseTitle, "It’s 2nd-Hand"},
+		{"ǆungla", TitleCaseTitle, "ǅungla"},
+		{"", TitleCaseTitle, ""},
+	}
+	for _, tt := range tests {
+		if got := ConvertCase(tt.title, tt.mode); got != tt.want {
+			t.Errorf("ConvertCase(%q, %q) = %q, want %q", tt.title, tt.mode, got, tt.want)
+		}
+	}
+	md := CaseTitles(sampleDocs(t), TitleCaseUpper)
+	want := []string{"GUIDES", "ADVANCED", "SCALING", "GETTING STARTED", "INTRO"}
+	if got := titlesInOrder(md, testTocOptions()); !reflect.DeepEqual(got, want) || md.Title != "DOCS" {
+		t.Errorf("CaseTitles() = %q, %q, want %q, %q", md.Title, got, "DOCS", want)
+	}
+	if got, want := RelPath(md.Children["intro.md"]), "intro.md"; got != want {
+		t.Errorf("path = %q, want %q", got, want)
+	}
+}
+
+func TestPrefixIcons(t *testing.T) {
+	md := MDFileInfo{
+		Title: "Docs",
+		IsDir: true,
+		Children: map[string]MDFileInfo{
+			"start.md": {Name: "start.md", Title: "Start", Level: 1, Frontmatter: map[string]string{"icon": "🚀"}},
+			"faq.md":   {Name: "faq.md", Title: "FAQ", Level: 1, Frontmatter: map[string]string{"emoji": "❓"}},
+			"both.md":  {Name: "both.md", Title: "Both", Level: 1, Frontmatter: map[string]string{"icon": "a", "emoji": "b"}},
+			"plain.md": {Name: "plain.md", Title: "Plain", Level: 1},
+			"guides": {Name: "guides", Title: "Guides", IsDir: true, Level: 1, Frontmatter: map[string]string{"icon": "📚"},
+				Children: map[string]MDFileInfo{
+					"deep.md": {Name: "deep.md", Title: "Deep", Level: 2, Frontmatter: map[string]string{"icon": "🔍"}},
+				}},
+		},
+	}
+	tests := []struct {
+		name string
+		keys []string
+		want map[string]string
+	}{
+		{"none", nil, map[string]string{"start.md": "Start", "faq.md": "FAQ", "both.md": "Both", "plain.md": "Plain", "guides": "Guides", "guides/deep.md": "Deep"}},
+		{"icon", []string{"icon"}, map[string]string{"start.md": "🚀 Start", "faq.md": "FAQ", "both.md": "a Both", "plain.md": "Plain", "guides": "📚 Guides", "guides/deep.md": "🔍 Deep"}},
+		{"emoji first", []string{"emoji", "icon"}, map[string]string{"start.md": "🚀 Start", "faq.md": "❓ FAQ", "both.md": "b Both", "plain.md": "Plain", "guides": "📚 Guides", "guides/deep.md": "🔍 Deep"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := PrefixIcons(md, tt.keys)
+			if got.Title != "Docs" {
+				t.Errorf("root title = %q, want %q", got.Title, "Docs")
+			}
+			for key, want := range tt.want {
+				node := got.Children[key]
+				if key == "guides/deep.md" {
+					node = got.Children["guides"].Children["deep.md"]
+				}
+				if node.Title != want {
+					t.Errorf("title of %s = %q, want %q", key, node.Title, want)
+				}
+			}
+			if md.Children["start.md"].Title != "Start" {
+				t.Error("PrefixIcons() changed the original tree")
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// sectionRegex matches a section of a TOC generated with UpdateToc, the key is captured in group 1,
+// the hash of the generated section in group 2 and the section content in group 3.
+var sectionRegex = regexp.MustCompile(`(?s)<!-- mdtocgen:section (.*?)(?: hash=([0-9a-f]+))? -->\n(.*?)<!-- /mdtocgen:section -->\n`)
+
+// UpdateToc regenerates the Markdown TOC previously written to outFile, keeping the text of the
+// sections which did not change since the file was written.
+//
+// Every top-level entry of the tree is a section, wrapped in `<!-- mdtocgen:section KEY hash=HASH -->`
+// and `<!-- /mdtocgen:section -->` markers, where HASH identifies the text generated for the section.
+// Every section is rendered again, and the text of outFile is kept when the section was generated
+// with the same hash, so that the sections whose entries and render options did not change, e.g.
+// with a deleted file or -section-numbers, are copied verbatim and unrelated parts of the file do not
+// show up in diffs. The other sections are replaced. If outFile does not exist, all the sections
+// are generated.
+//
+// Parameters:
+// - outFile: the path of the previously generated TOC.
+// - md: the MDFileInfo object representing the root directory.
+// - opts: the options used to render the TOC.
+//
+// Returns:
+// - string: the updated TOC.
+// - error: an error if outFile exists but could not be read.
+func UpdateToc(outFile string, md MDFileInfo, opts TocOptions) (string, error) {
+	type previousSection struct {
+		hash, text string
+	}
+	previous := make(map[string]previousSection)
+	content, err := os.ReadFile(outFile)
+	if err == nil {
+		for _, match := range sectionRegex.FindAllStringSubmatch(string(content), -1) {
+			previous[match[1]] = previousSection{hash: match[2], text: match[3]}
+		}
+	} else if !os.IsNotExist(err) {
+		return "", err
+	}
+
+	toc := RootHeading(md, opts)
+	if opts.SectionJumplist {
+		// The jumplist is cheap and lists all the sections, it is always regenerated
+		toc += SectionJumplist(md, opts)
+	}
+	for _, key := range SortedChildKeys(md, opts) {
+		section := strings.Trim(CreateTocTree(md.Children[key], opts), "\n") + "\n\n"
+		hash := SectionHash(section)
+		if prev, ok := previous[key]; ok && prev.hash == hash {
+			section = prev.text
+		}
+		toc += "\n<!-- mdtocgen:section " + key + " hash=" + hash + " -->\n" + section + "<!-- /mdtocgen:section -->\n"
+	}
+	return toc, nil
+}
+
+// SectionHash returns the hash identifying the generated text of a section of UpdateToc.
+func SectionHash(section string) string {
+	sum := sha256.Sum256([]byte(section))
+	return hex.EncodeToString(sum[:8])
+}
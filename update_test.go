@@ -0,0 +1,123 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUpdateToc(t *testing.T) {
+	tests := []struct {
+		name     string
+		change   func(t *testing.T, dir string)
+		numbered bool
+		want     []string
+		notWant  []string
+	}{
+		{
+			name: "unchanged sections are kept",
+			want: []string{"EDITED guides", "EDITED intro"},
+		},
+		{
+			name: "deleted nested file",
+			change: func(t *testing.T, dir string) {
+				if err := os.Remove(filepath.Join(dir, "guides", "advanced", "scaling.md")); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want:    []string{"EDITED intro", "## guides"},
+			notWant: []string{"Scaling", "EDITED guides"},
+		},
+		{
+			name: "retitled file",
+			change: func(t *testing.T, dir string) {
+				if err := os.WriteFile(filepath.Join(dir, "intro.md"), []byte("# Welcome\n"), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want:    []string{"EDITED guides", "[Welcome]"},
+			notWant: []string{"EDITED intro"},
+		},
+		{
+			name:     "section numbers",
+			numbered: true,
+			want:     []string{"## 1 guides", "## [2 Intro]"},
+			notWant:  []string{"EDITED"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeTree(t, map[string]string{
+				"intro.md":                   "# Intro\n",
+				"guides/start.md":            "# Getting Started\n",
+				"guides/advanced/scaling.md": "# Scaling\n",
+			})
+			outFile := filepath.Join(t.TempDir(), "TOC.md")
+			opts := testTocOptions()
+			toc, err := UpdateToc(outFile, listTree(t, dir, testListOptions()), opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Edit the generated sections by hand, the edits show whether a section was kept
+			toc = strings.Replace(toc, "## guides", "## EDITED guides", 1)
+			toc = strings.Replace(toc, "## [Intro]", "## [EDITED intro]", 1)
+			if err := os.WriteFile(outFile, []byte(toc), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.change != nil {
+				tt.change(t, dir)
+			}
+			md := listTree(t, dir, testListOptions())
+			if tt.numbered {
+				md = NumberSections(md, "", opts)
+			}
+			got, err := UpdateToc(outFile, md, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("updated TOC does not contain %q:\n%s", want, got)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(got, notWant) {
+					t.Errorf("updated TOC contains %q:\n%s", notWant, got)
+				}
+			}
+			if n := strings.Count(got, "<!-- /mdtocgen:section -->"); n != 2 {
+				t.Errorf("updated TOC has %d sections, want 2:\n%s", n, got)
+			}
+		})
+	}
+}
+
+func TestUpdateTocMissingFile(t *testing.T) {
+	md := sampleDocs(t)
+	opts := testTocOptions()
+	got, err := UpdateToc(filepath.Join(t.TempDir(), "TOC.md"), md, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(got, "# Docs\n") || !strings.Contains(got, "<!-- mdtocgen:section guides hash=") || !strings.Contains(got, "- [Getting Started]") {
+		t.Errorf("UpdateToc() without a previous file =\n%s", got)
+	}
+}
+
+func TestUpdateTocLegacyMarkers(t *testing.T) {
+	md := sampleDocs(t)
+	outFile := filepath.Join(t.TempDir(), "TOC.md")
+	legacy := "# Docs\n\n<!-- mdtocgen:section intro.md -->\n## [Stale](./intro.md)\n\n<!-- /mdtocgen:section -->\n"
+	if err := os.WriteFile(outFile, []byte(legacy), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := UpdateToc(outFile, md, testTocOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(got, "Stale") || !strings.Contains(got, "[Intro]") {
+		t.Errorf("the section without a hash was not regenerated:\n%s", got)
+	}
+}
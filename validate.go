@@ -0,0 +1,61 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Validate checks the files of the tree before the TOC is generated and reports all the problems
+// at once: the files which cannot be read, the files without a title for the title strategy, and
+// the paths which only differ by case, which conflict on case-insensitive file systems.
+//
+// Parameters:
+// - dirPath: the directory the tree was listed from, or an empty string not to read the files again.
+// - md: the MDFileInfo object representing the root directory.
+//
+// Returns:
+// - error: the problems joined with errors.Join, or nil if there are none.
+func Validate(dirPath string, md MDFileInfo) error {
+	var errs []error
+	seen := make(map[string]string)
+	for _, entry := range FlattenFiles(md, TocOptions{SortAsc: true}) {
+		relPath := RelPath(entry.File)
+		if dirPath != "" {
+			if _, err := os.ReadFile(filepath.Join(dirPath, filepath.FromSlash(relPath))); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+		}
+		if strings.TrimSpace(entry.File.Title) == "" {
+			errs = append(errs, fmt.Errorf("%s: no title found", relPath))
+		}
+		key := strings.ToLower(relPath)
+		if other, ok := seen[key]; ok {
+			errs = append(errs, fmt.Errorf("%s: conflicts with %s on case-insensitive file systems", relPath, other))
+		} else {
+			seen[key] = relPath
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// UntitledFiles returns the paths of the files for which the title strategy found no title,
+// relative to the root directory, in rendering order.
+//
+// Parameters:
+// - md: the MDFileInfo object representing the root directory.
+//
+// Returns:
+// - []string: the paths of the untitled files, empty if all the files have a title.
+func UntitledFiles(md MDFileInfo) []string {
+	var paths []string
+	for _, entry := range FlattenFiles(md, TocOptions{SortAsc: true}) {
+		if strings.TrimSpace(entry.File.Title) == "" {
+			paths = append(paths, RelPath(entry.File))
+		}
+	}
+	return paths
+}
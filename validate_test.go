@@ -0,0 +1,83 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	files := map[string]MDFileInfo{
+		"intro.md": {Name: "intro.md", Title: "Intro", Path: "./intro.md"},
+		"Intro.md": {Name: "Intro.md", Title: "Intro", Path: "./Intro.md"},
+		"empty.md": {Name: "empty.md", Title: " ", Path: "./empty.md"},
+		"guides": {Name: "guides", IsDir: true, Path: "./guides", Children: map[string]MDFileInfo{
+			"start.md": {Name: "start.md", Path: "./guides/start.md"},
+		}},
+	}
+	tests := []struct {
+		name     string
+		children map[string]MDFileInfo
+		want     []string
+	}{
+		{"valid", map[string]MDFileInfo{"intro.md": files["intro.md"]}, nil},
+		{"all problems", files, []string{
+			"empty.md: no title found",
+			"guides/start.md: no title found",
+			"intro.md: conflicts with Intro.md on case-insensitive file systems",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate("", MDFileInfo{IsDir: true, Children: tt.children})
+			if tt.want == nil {
+				if err != nil {
+					t.Errorf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("Validate() = nil, want an error")
+			}
+			if want := strings.Join(tt.want, "\n"); err.Error() != want {
+				t.Errorf("Validate() =\n%v\nwant\n%s", err, want)
+			}
+		})
+	}
+}
+
+func TestValidateUnreadable(t *testing.T) {
+	dir := writeTree(t, map[string]string{"intro.md": "# Intro\n"})
+	md := listTree(t, dir, testListOptions())
+	md.Children["gone.md"] = MDFileInfo{Name: "gone.md", Title: "Gone", Path: "./gone.md"}
+	err := Validate(dir, md)
+	if err == nil || !strings.Contains(err.Error(), "gone.md") || strings.Contains(err.Error(), "intro.md") {
+		t.Errorf("Validate() = %v, want only the unreadable gone.md", err)
+	}
+}
+
+func TestUntitledFiles(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  []string
+	}{
+		{"all titled", map[string]string{"intro.md": "# Intro\n"}, nil},
+		{"untitled", map[string]string{
+			"intro.md":        "# Intro\n",
+			"notes.md":        "just text\n",
+			"guides/start.md": "",
+			"guides/faq.md":   "## FAQ\n",
+		}, []string{"guides/faq.md", "guides/start.md", "notes.md"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := testListOptions()
+			opts.TitleStrategy = []TitleSource{H1Title}
+			md := listTree(t, writeTree(t, tt.files), opts)
+			if got := UntitledFiles(md); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("UntitledFiles() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
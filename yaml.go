@@ -0,0 +1,49 @@
+package main
+
+import (
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// yamlNode is a node of the YAML output.
+type yamlNode struct {
+	Title    string     `yaml:"title"`
+	Path     string     `yaml:"path,omitempty"`
+	Children []yamlNode `yaml:"children,omitempty"`
+}
+
+// CreateYAML generates the tree as a YAML document: every node has a title, the files and the
+// linked directories have a path relative to the root directory, and the directories have their
+// children in rendering order, as YAML mappings do not keep the order of the tree's maps.
+//
+// Parameters:
+// - md: the MDFileInfo object representing the root directory.
+// - opts: the options used to render the TOC.
+//
+// Returns:
+// - string: the generated YAML.
+func CreateYAML(md MDFileInfo, opts TocOptions) string {
+	var sb strings.Builder
+	encoder := yaml.NewEncoder(&sb)
+	encoder.SetIndent(len(opts.Indent))
+	if err := encoder.Encode(yamlTree(md, opts)); err != nil {
+		return ""
+	}
+	encoder.Close()
+	return sb.String()
+}
+
+// yamlTree converts md and its descendants to yamlNode.
+func yamlTree(md MDFileInfo, opts TocOptions) yamlNode {
+	node := yamlNode{Title: md.Title}
+	if !md.IsDir {
+		node.Path = RelPath(md)
+	} else if md.LinkPath != "" {
+		node.Path = RelPath(MDFileInfo{Path: md.LinkPath})
+	}
+	for _, key := range SortedChildKeys(md, opts) {
+		node.Children = append(node.Children, yamlTree(md.Children[key], opts))
+	}
+	return node
+}
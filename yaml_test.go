@@ -0,0 +1,48 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestCreateYAML(t *testing.T) {
+	want := "title: Docs\n" +
+		"children:\n" +
+		"  - title: guides\n" +
+		"    children:\n" +
+		"      - title: advanced\n" +
+		"        children:\n" +
+		"          - title: Scaling\n" +
+		"            path: guides/advanced/scaling.md\n" +
+		"      - title: Getting Started\n" +
+		"        path: guides/start.md\n" +
+		"  - title: Intro\n" +
+		"    path: intro.md\n"
+	if got := CreateYAML(sampleDocs(t), testTocOptions()); got != want {
+		t.Errorf("CreateYAML() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestCreateYAMLRoundTrip(t *testing.T) {
+	md := MDFileInfo{Title: "Docs: 2024", IsDir: true, Children: map[string]MDFileInfo{
+		"guides": {Name: "guides", Title: "true", IsDir: true, LinkPath: "./guides/README.md", Children: map[string]MDFileInfo{
+			"a.md": {Name: "a.md", Title: "# Not a comment", Path: "./guides/a.md"},
+		}},
+		"b.md": {Name: "b.md", Title: "10", Path: "./b.md"},
+	}}
+	var got yamlNode
+	if err := yaml.Unmarshal([]byte(CreateYAML(md, testTocOptions())), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := yamlNode{Title: "Docs: 2024", Children: []yamlNode{
+		{Title: "10", Path: "b.md"},
+		{Title: "true", Path: "guides/README.md", Children: []yamlNode{
+			{Title: "# Not a comment", Path: "guides/a.md"},
+		}},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CreateYAML() decoded to %+v, want %+v", got, want)
+	}
+}